@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// A puzzle read from a collection with one puzzle per line
+type entry struct {
+	line  int
+	grid  sudoku.Grid
+	rules sudoku.Rules // Classic rules of the board size the line gives
+	err   error        // Why the puzzle cannot be played, if it cannot
+}
+
+// Read a collection from a file or stdin and check every puzzle in it.
+// Blank lines and lines starting with '#' are skipped, and anything after
+// the first field of a line is ignored.
+func readEntries(file string, fn func(entry)) error {
+	var r io.Reader = os.Stdin
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		e := entry{line: line}
+		e.grid, e.err = sudoku.ParseGrid(fields[0], sudoku.FormatLine)
+		if e.err == nil {
+			e.rules, e.err = sudoku.ParseRules(fields[0], sudoku.FormatLine)
+		}
+		if e.err == nil {
+			_, e.err = e.rules.FromGrid(e.grid)
+		}
+		fn(e)
+	}
+	return scanner.Err()
+}
+
+// Flag puzzles that cannot be played
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	file := flags.String("file", "", "read puzzles from a file instead of stdin, one per line")
+	flags.Parse(args)
+
+	checked, invalid := 0, 0
+	err := readEntries(*file, func(e entry) {
+		checked++
+		if e.err != nil {
+			invalid++
+			fmt.Printf("line %d: %v\n", e.line, e.err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d puzzles checked, %d invalid\n", checked, invalid)
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d puzzles are invalid", invalid, checked)
+	}
+	return nil
+}
+
+// Rate every puzzle of a collection
+func runGrade(args []string) error {
+	flags := flag.NewFlagSet("grade", flag.ExitOnError)
+	file := flags.String("file", "", "read puzzles from a file instead of stdin, one per line")
+	flags.Parse(args)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Line\tClues\tScore\tDifficulty\tHardest\tStatus")
+
+	checked, invalid := 0, 0
+	err := readEntries(*file, func(e entry) {
+		checked++
+		if e.err != nil {
+			invalid++
+			fmt.Fprintf(tw, "%d\t-\t-\t-\t-\t%v\n", e.line, e.err)
+			return
+		}
+
+		r := e.rules.Grade(e.grid)
+		difficulty, hardest, status := r.Difficulty().String(), hardestTechnique(r), "ok"
+		if !r.Solved {
+			difficulty, status = "-", "needs techniques beyond "+sudoku.XYZWing.String()
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%s\n",
+			e.line, e.grid.Clues(), r.Score, difficulty, hardest, status)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d puzzles are invalid", invalid, checked)
+	}
+	return nil
+}
+
+// Hardest technique of a rating. When logic got stuck the technique needed
+// is unknown, so this names the hardest one used before that.
+func hardestTechnique(r sudoku.Rating) string {
+	switch {
+	case r.Solved:
+		return r.Hardest.String()
+	case r.Uses == 0:
+		return "-" // Stuck before the first step
+	default:
+		return "stuck after " + r.Hardest.String()
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Print new puzzles in one of the export formats
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	difficultyName := flags.String("difficulty", "medium", "puzzle difficulty: easy, medium, hard or expert")
+	formatName := flags.String("format", "line", "output format: line, sdk, sdm, ss or json")
+	count := flags.Int("count", 1, "number of puzzles to generate")
+	seed := flags.Int64("seed", -1, "seed of the first puzzle; the others use the following seeds")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of puzzles to generate in parallel")
+	output := flags.String("output", "", "file to write to instead of stdout")
+	symmetryName := flags.String("symmetry", "rotate180", "pattern of givens: rotate180, rotate90, diagonal, mirror or none")
+	clues := flags.String("clues", "", "number of givens, exact (25) or a range (22-26)")
+	timeout := flags.Duration("timeout", 0, "give up after this long, e.g. 30s")
+	variantName := flags.String("variant", "classic", "puzzle variant: classic, jigsaw, killer, x, hyper, or jigsaw,killer for both (JSON format only unless classic)")
+	size := flags.Int("size", 9, "board size: 4, 6, 9, 12 or 16; variants are 9x9 only")
+	flags.Parse(args)
+
+	difficulty, err := sudoku.ParseDifficulty(*difficultyName)
+	if err != nil {
+		return err
+	}
+	format, err := sudoku.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	symmetry, err := sudoku.ParseSymmetry(*symmetryName)
+	if err != nil {
+		return err
+	}
+	variantOpts, err := variantOptions(*variantName, *size)
+	if err != nil {
+		return err
+	}
+	opts := append([]sudoku.Option{sudoku.WithSymmetry(symmetry)}, variantOpts...)
+	if *clues != "" {
+		lo, hi, err := parseRange(*clues)
+		if err != nil {
+			return fmt.Errorf("invalid --clues: %w", err)
+		}
+		opts = append(opts, sudoku.WithClueRange(lo, hi))
+	}
+	if *timeout > 0 {
+		opts = append(opts, sudoku.WithTimeout(*timeout))
+	}
+	if len(variantOpts) > 0 && format != sudoku.FormatJSON {
+		return fmt.Errorf("jigsaw, killer, X and Hyper puzzles need --format json to keep their rules")
+	}
+	if *size != 9 {
+		opts = append(opts, sudoku.WithSize(*size))
+	}
+	if *seed < 0 {
+		*seed = sudoku.RandomSeed()
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	// Formats spanning several lines get a blank line between puzzles
+	multiline := format == sudoku.FormatSDK || format == sudoku.FormatSS || format == sudoku.FormatJSON
+	first := true
+
+	err = sudoku.Generator{}.GenerateBatch(difficulty, *seed, *count, *workers, func(s sudoku.Sudoku) error {
+		text, err := s.Export(format)
+		if err != nil {
+			return err
+		}
+		if multiline && !first {
+			w.WriteString("\n")
+		}
+		first = false
+		_, err = w.WriteString(text)
+		return err
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Parse "n" or "lo-hi"
+func parseRange(s string) (lo, hi int, err error) {
+	loText, hiText, isRange := strings.Cut(s, "-")
+	if lo, err = strconv.Atoi(loText); err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return lo, lo, nil
+	}
+	if hi, err = strconv.Atoi(hiText); err != nil {
+		return 0, 0, err
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("%d is below %d", hi, lo)
+	}
+	return lo, hi, nil
+}
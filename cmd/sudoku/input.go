@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Read puzzle text from a file, from a command line argument, or from
+// stdin when neither is given, and work out its format
+func readPuzzle(file, arg string) (string, sudoku.Format, error) {
+	switch {
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", 0, err
+		}
+		return string(data), sudoku.FormatForFile(file, string(data)), nil
+	case arg != "":
+		return arg, sudoku.DetectFormat(arg), nil
+	default:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", 0, err
+		}
+		return string(data), sudoku.DetectFormat(string(data)), nil
+	}
+}
+
+// Prefix an error about a puzzle with the file it came from, if any
+func inputError(file string, err error) error {
+	if file == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", file, err)
+}
+
+// Generation options for the --variant flag: one variant, or
+// "jigsaw,killer" for killer cages on jigsaw regions, the only variants
+// that combine. Variants are only played on 9x9 boards.
+func variantOptions(value string, size int) ([]sudoku.Option, error) {
+	var opts []sudoku.Option
+	var variants []sudoku.Variant
+	for _, name := range strings.Split(value, ",") {
+		v, err := sudoku.ParseVariant(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+		opts = append(opts, v.Options()...)
+	}
+
+	slices.Sort(variants)
+	if len(variants) > 1 && !slices.Equal(variants, []sudoku.Variant{sudoku.VariantJigsaw, sudoku.VariantKiller}) {
+		return nil, fmt.Errorf("variant %q: only jigsaw and killer combine, as jigsaw,killer", value)
+	}
+	if len(opts) > 0 && size != 9 {
+		return nil, fmt.Errorf("jigsaw, killer, X and Hyper puzzles are only played on 9x9 boards")
+	}
+	return opts, nil
+}
@@ -3,24 +3,53 @@ package main
 import (
 	"fmt"
 	"os"
-
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/jensderond/sudoku-cli/internal/game"
-	"github.com/jensderond/sudoku-cli/internal/sudoku"
-	"github.com/jensderond/sudoku-cli/internal/ui"
+	"strings"
 )
 
+const usage = `Usage: sudoku [command] [flags]
+
+Commands:
+  play      Play in the terminal (default)
+  generate  Print new puzzles, one per line
+  solve     Solve a puzzle, optionally explaining each step
+  grade     Rate a collection of puzzles, one per line
+  validate  Check that every puzzle in a collection has one solution
+  stats     Show statistics of finished games
+
+Run "sudoku <command> -h" for the flags of a command.
+`
+
 func main() {
-	// Initialize game
-	g := game.New(sudoku.Medium)
+	// Without a command, flags belong to play
+	args := os.Args[1:]
+	command := "play"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	// Create UI model
-	model := ui.NewModel(g)
+	var err error
+	switch command {
+	case "play":
+		err = runPlay(args)
+	case "generate":
+		err = runGenerate(args)
+	case "solve":
+		err = runSolve(args)
+	case "grade":
+		err = runGrade(args)
+	case "validate":
+		err = runValidate(args)
+	case "stats":
+		err = runStats(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		err = fmt.Errorf("unknown command %q", command)
+	}
 
-	// Create and run the program
-	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v", err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/internal/pool"
+	"github.com/jensderond/sudoku-cli/internal/storage"
+	"github.com/jensderond/sudoku-cli/internal/ui"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Ready puzzles kept per difficulty
+const poolSize = 2
+
+// Start the interactive game
+func runPlay(args []string) error {
+	flags := flag.NewFlagSet("play", flag.ExitOnError)
+	difficultyName := flags.String("difficulty", "medium", "puzzle difficulty: easy, medium, hard or expert")
+	seed := flags.Int64("seed", -1, "seed to generate the puzzle from, for sharing puzzles")
+	resume := flags.Bool("resume", false, "resume the game saved on quit")
+	file := flags.String("file", "", "play the puzzle in a .sdk, .sdm, .ss or one-line file")
+	puzzle := flags.String("puzzle", "", "play a puzzle given as 81 characters, or 16 to 256 for other sizes, '.' or '0' for blanks")
+	poolCache := flags.Bool("pool-cache", true, "keep unused pre-generated puzzles between runs")
+	variantName := flags.String("variant", "classic", "puzzle variant: classic, jigsaw, killer, x, hyper, or jigsaw,killer for both")
+	size := flags.Int("size", 9, "board size: 4, 6, 9, 12 or 16; variants are 9x9 only")
+	flags.Parse(args)
+
+	difficulty, err := sudoku.ParseDifficulty(*difficultyName)
+	if err != nil {
+		return err
+	}
+	if _, err := sudoku.SizedRules(*size); err != nil {
+		return err
+	}
+	variantOpts, err := variantOptions(*variantName, *size)
+	if err != nil {
+		return err
+	}
+
+	// Keep puzzles ready in the background so new games start instantly
+	puzzles := pool.New(poolSize)
+	if *poolCache {
+		if err := storage.LoadPool(puzzles); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring saved puzzles: %v\n", err)
+		}
+	}
+	puzzles.Start(1)
+	game.Puzzles = puzzles
+	defer func() {
+		puzzles.Close()
+		if *poolCache {
+			if err := storage.SavePool(puzzles); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not save puzzles: %v\n", err)
+			}
+		}
+	}()
+
+	// Flags that pick the game skip the menu
+	picked := false
+	flags.Visit(func(f *flag.Flag) {
+		picked = picked || f.Name != "pool-cache"
+	})
+
+	// Initialize game
+	var g *game.Game
+	if *resume {
+		g, err = storage.LoadGame()
+		if errors.Is(err, storage.ErrNoSave) {
+			fmt.Println("No saved game found, starting a new one")
+		} else if err != nil {
+			return err
+		}
+	}
+	if g == nil && (*file != "" || *puzzle != "") {
+		g, err = importGame(*file, *puzzle)
+		if err != nil {
+			return err
+		}
+	}
+	if g == nil && picked {
+		opts := variantOpts
+		if *seed >= 0 {
+			opts = append(opts, sudoku.WithSeed(*seed))
+		}
+		if *size != 9 {
+			opts = append(opts, sudoku.WithSize(*size))
+		}
+		g, err = game.NewWithOptions(difficulty, opts...)
+		if err != nil {
+			return fmt.Errorf("could not generate a puzzle: %w", err)
+		}
+	}
+
+	// Create UI model, showing the menu if no game was picked
+	model := ui.NewModel(g)
+
+	// Create and run the program
+	p := tea.NewProgram(model)
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+
+	if err := model.SaveError(); err != nil {
+		return fmt.Errorf("could not save game: %w", err)
+	}
+	return nil
+}
+
+// Load a puzzle from a file or from the --puzzle flag
+func importGame(file, puzzle string) (*game.Game, error) {
+	data, format, err := readPuzzle(file, puzzle)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := sudoku.Import(data, format)
+	if err != nil {
+		return nil, inputError(file, err)
+	}
+	return game.NewFromPuzzle(s), nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Solution as printed with --json
+type solveOutput struct {
+	Puzzle     string       `json:"puzzle"`
+	Solution   string       `json:"solution"`
+	Difficulty string       `json:"difficulty"`
+	Score      int          `json:"score"`
+	Hardest    string       `json:"hardest_technique"`
+	Logical    bool         `json:"logical"` // Whether logic alone solves the puzzle
+	Steps      []stepOutput `json:"steps,omitempty"`
+}
+
+type stepOutput struct {
+	Technique    string       `json:"technique"`
+	Description  string       `json:"description"`
+	Placements   []cellOutput `json:"placements,omitempty"`
+	Eliminations []cellOutput `json:"eliminations,omitempty"`
+}
+
+type cellOutput struct {
+	Cell  string `json:"cell"`
+	Digit int    `json:"digit"`
+}
+
+// Solve a puzzle from a file, an argument or stdin
+func runSolve(args []string) error {
+	flags := flag.NewFlagSet("solve", flag.ExitOnError)
+	file := flags.String("file", "", "read the puzzle from a file instead of stdin")
+	formatName := flags.String("format", "ss", "output format: line, sdk, sdm or ss")
+	explain := flags.Bool("explain", false, "list the logical steps that solve the puzzle")
+	jsonOut := flags.Bool("json", false, "print machine-readable JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: sudoku solve [flags] [puzzle]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	format, err := sudoku.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	data, inFormat, err := readPuzzle(*file, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	grid, err := sudoku.ParseGrid(data, inFormat)
+	if err != nil {
+		return inputError(*file, err)
+	}
+	rules, err := sudoku.ParseRules(data, inFormat)
+	if err != nil {
+		return inputError(*file, err)
+	}
+	s, err := rules.FromGrid(grid)
+	if err != nil {
+		return inputError(*file, err)
+	}
+
+	var steps []sudoku.Step
+	if *explain {
+		steps = rules.SolveLogically(grid).Steps
+	}
+
+	if *jsonOut {
+		return writeSolveJSON(s, steps)
+	}
+
+	for i, step := range steps {
+		fmt.Printf("%3d. %s\n", i+1, step)
+	}
+	if *explain {
+		if rating := rules.Grade(grid); !rating.Solved {
+			fmt.Println("     No technique applies from here; the rest needs trial and error.")
+		}
+		fmt.Println()
+	}
+
+	out, err := (&sudoku.Sudoku{Grid: s.Solution, Rules: s.Rules}).Export(format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func writeSolveJSON(s sudoku.Sudoku, steps []sudoku.Step) error {
+	rating := s.Rules.Grade(s.Grid)
+	out := solveOutput{
+		Puzzle:     s.Grid.String(),
+		Solution:   s.Solution.String(),
+		Difficulty: min(rating.Difficulty(), sudoku.Expert).String(),
+		Score:      rating.Score,
+		Hardest:    hardestTechnique(rating),
+		Logical:    rating.Solved,
+	}
+
+	for _, step := range steps {
+		so := stepOutput{
+			Technique:   step.Technique.String(),
+			Description: step.String(),
+		}
+		for _, p := range step.Placements {
+			so.Placements = append(so.Placements, cellOutput{p.Cell.String(), p.Digit})
+		}
+		for _, e := range step.Eliminations {
+			so.Eliminations = append(so.Eliminations, cellOutput{e.Cell.String(), e.Digit})
+		}
+		out.Steps = append(out.Steps, so)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
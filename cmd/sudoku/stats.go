@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/jensderond/sudoku-cli/internal/stats"
+	"github.com/jensderond/sudoku-cli/internal/storage"
+)
+
+// Print statistics of finished games
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	flags.Parse(args)
+
+	l, err := storage.LoadStats()
+	if err != nil {
+		return err
+	}
+	return stats.WriteTable(os.Stdout, l)
+}
@@ -4,73 +4,261 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/jensderond/sudoku-cli/internal/sudoku"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
 )
 
 // Game state
 type Game struct {
 	Sudoku     sudoku.Sudoku
 	Difficulty sudoku.Difficulty
+	Variant    sudoku.Variant // Kind of puzzle new games are played with
+	Size       int            // Board size new games are played on
 	Lives      int
 	StartTime  time.Time
 	Elapsed    time.Duration
+	Paused     bool // The clock is stopped while the game is left for the menu
 	Solved     bool
 	GameOver   bool
+	NotesMode  bool // Number keys toggle pencil marks instead of placing digits
+	History    History
+	Hint       *Hint // Hint currently shown, if any
+	HintsUsed  int
+}
+
+// Lives at the start of a game
+const MaxLives = 3
+
+// Source of ready puzzles, such as a pool filled in the background
+type PuzzleSource interface {
+	Take(d sudoku.Difficulty) (sudoku.Sudoku, bool)
+}
+
+// Where New and Reset look for a puzzle before generating one. When nil,
+// puzzles are always generated on the spot.
+var Puzzles PuzzleSource
+
+// Take a ready puzzle from Puzzles, if there is one
+func TakePuzzle(d sudoku.Difficulty) (sudoku.Sudoku, bool) {
+	if Puzzles == nil {
+		return sudoku.Sudoku{}, false
+	}
+	return Puzzles.Take(d)
+}
+
+// Take a ready puzzle, or generate one if none is available. Puzzles with
+// generation options, such as jigsaws, are always generated.
+func newPuzzle(d sudoku.Difficulty, opts ...sudoku.Option) (sudoku.Sudoku, error) {
+	if len(opts) == 0 {
+		if s, ok := TakePuzzle(d); ok {
+			return s, nil
+		}
+	}
+	return sudoku.Generate(d, opts...)
 }
 
 // Create a new game
 func New(difficulty sudoku.Difficulty) *Game {
+	s, ok := TakePuzzle(difficulty)
+	if !ok {
+		s = sudoku.New(difficulty)
+	}
+	return newGame(s, difficulty)
+}
+
+// Create a new game from a puzzle generated with options, such as a jigsaw.
+// Fails if no puzzle could be generated, e.g. when a jigsaw layout cannot
+// be filled or the context is cancelled.
+func NewWithOptions(difficulty sudoku.Difficulty, opts ...sudoku.Option) (*Game, error) {
+	s, err := newPuzzle(difficulty, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newGame(s, difficulty), nil
+}
+
+// Create a new game from a shared seed
+func NewWithSeed(difficulty sudoku.Difficulty, seed int64) *Game {
+	return newGame(sudoku.NewWithSeed(difficulty, seed), difficulty)
+}
+
+// Create a game from a puzzle generated elsewhere, such as in the background
+func NewWithPuzzle(s sudoku.Sudoku, difficulty sudoku.Difficulty) *Game {
+	return newGame(s, difficulty)
+}
+
+// Difficulty of the daily puzzle
+const DailyDifficulty = sudoku.Medium
+
+// Seed of the daily puzzle, the same for every player on a given day: the
+// date as a number, e.g. 20261017
+func DailySeed(day time.Time) int64 {
+	y, m, d := day.Date()
+	return int64(y*10000 + int(m)*100 + d)
+}
+
+// Create a game from an imported puzzle, rated to find its difficulty
+func NewFromPuzzle(s sudoku.Sudoku) *Game {
+	difficulty := min(s.Rules.Grade(s.Grid).Difficulty(), sudoku.Expert)
+	return newGame(s, difficulty)
+}
+
+func newGame(s sudoku.Sudoku, difficulty sudoku.Difficulty) *Game {
 	return &Game{
-		Sudoku:     sudoku.New(difficulty),
+		Sudoku:     s,
 		Difficulty: difficulty,
-		Lives:      3,
+		Variant:    s.Rules.Variant(),
+		Size:       s.Size(),
+		Lives:      MaxLives,
 		StartTime:  time.Now(),
 		Solved:     false,
 		GameOver:   false,
 	}
 }
 
-// Reset game with new puzzle
-func (g *Game) Reset() {
-	g.Sudoku = sudoku.New(g.Difficulty)
-	g.Lives = 3
+// Reset game with a new puzzle of the same kind. The game is left as it
+// is if no puzzle could be generated.
+func (g *Game) Reset() error {
+	s, err := newPuzzle(g.Difficulty, g.PuzzleOptions()...)
+	if err != nil {
+		return err
+	}
+	g.Start(s, g.Difficulty)
+	return nil
+}
+
+// Generation options for the next puzzle: another of the same kind as the
+// current one, unless a different variant or size has been picked since
+func (g *Game) PuzzleOptions() []sudoku.Option {
+	if g.Variant == g.Sudoku.Rules.Variant() && g.Size == g.Sudoku.Size() {
+		return g.Sudoku.Rules.Options()
+	}
+	opts := g.Variant.Options()
+	if g.Size != 0 && g.Size != 9 {
+		opts = append(opts, sudoku.WithSize(g.Size))
+	}
+	return opts
+}
+
+// Start over with a puzzle generated elsewhere
+func (g *Game) Start(s sudoku.Sudoku, difficulty sudoku.Difficulty) {
+	g.Sudoku = s
+	g.Difficulty = difficulty
+	g.Lives = MaxLives
 	g.StartTime = time.Now()
 	g.Elapsed = 0
+	g.Paused = false
 	g.Solved = false
 	g.GameOver = false
+	g.History = History{}
+	g.Hint = nil
+	g.HintsUsed = 0
+}
+
+// Check if the game has ended, won or lost
+func (g *Game) Finished() bool {
+	return g.Solved || g.GameOver
+}
+
+// Points for a solved game: a base that grows with the difficulty, minus
+// penalties for lost lives, hints and every second on the clock
+func (g *Game) Score() int {
+	if !g.Solved {
+		return 0
+	}
+	score := 1000 * (int(g.Difficulty) + 1)
+	score -= 250 * (MaxLives - g.Lives)
+	score -= hintPenalty * g.HintsUsed
+	score -= int(g.Elapsed.Seconds())
+	return max(score, 0)
 }
 
 // Update elapsed time
 func (g *Game) UpdateTime() {
-	if !g.Solved && !g.GameOver {
+	if !g.Solved && !g.GameOver && !g.Paused {
 		g.Elapsed = time.Since(g.StartTime)
 	}
 }
 
+// Stop the clock, such as while the game is left for the menu
+func (g *Game) Pause() {
+	g.UpdateTime()
+	g.Paused = true
+}
+
+// Restart the clock from the elapsed time after a pause
+func (g *Game) Resume() {
+	if g.Paused {
+		g.StartTime = time.Now().Add(-g.Elapsed)
+		g.Paused = false
+	}
+}
+
 // Switch to next difficulty
 func (g *Game) SwitchDifficulty() {
 	g.Difficulty = g.Difficulty.Next()
 }
 
+// Switch to next variant. The variants are only played on 9x9.
+func (g *Game) SwitchVariant() {
+	g.Variant = g.Variant.Next()
+	if g.Variant != sudoku.VariantClassic {
+		g.Size = 9
+	}
+}
+
+// Switch to the next board size, from 4x4 up to 16x16. Other sizes than
+// 9x9 are played with classic rules.
+func (g *Game) SwitchSize() {
+	g.Size = NextSize(g.Size)
+	if g.Size != 9 {
+		g.Variant = sudoku.VariantClassic
+	}
+}
+
+// Board size after the given one, going back to 4x4 after 16x16
+func NextSize(size int) int {
+	for i, n := range sudoku.Sizes[:len(sudoku.Sizes)-1] {
+		if n == size {
+			return sudoku.Sizes[i+1]
+		}
+	}
+	return sudoku.Sizes[0]
+}
+
+// Switch between placing digits and editing notes
+func (g *Game) ToggleNotesMode() {
+	g.NotesMode = !g.NotesMode
+}
+
 // Handle number input
 func (g *Game) HandleNumberInput(num int) bool {
-	if g.Solved || g.GameOver {
+	if g.Solved || g.GameOver || num > g.Sudoku.Size() {
 		return false
 	}
 
-	oldValue := g.Sudoku.Grid[g.Sudoku.CursorY][g.Sudoku.CursorX]
+	move := g.beginMove()
+
+	if g.NotesMode {
+		if !g.Sudoku.ToggleNote(num) {
+			return false
+		}
+		g.finishMove(move)
+		return true
+	}
 
 	if !g.Sudoku.SetValue(num) {
 		return false // Cannot modify initial cells
 	}
 
 	// Check if the move is incorrect
-	if !g.Sudoku.IsCurrentMoveCorrect() && oldValue != num {
+	if !g.Sudoku.IsCurrentMoveCorrect() && move.OldValue != num {
 		g.Lives--
+		move.LostLife = true
 		if g.Lives <= 0 {
 			g.GameOver = true
 		}
 	}
+	g.finishMove(move)
 
 	// Check if solved
 	if g.Sudoku.IsSolved() {
@@ -85,7 +273,13 @@ func (g *Game) HandleClear() bool {
 	if g.Solved || g.GameOver {
 		return false
 	}
-	return g.Sudoku.ClearCurrentCell()
+
+	move := g.beginMove()
+	if !g.Sudoku.ClearCurrentCell() {
+		return false
+	}
+	g.finishMove(move)
+	return true
 }
 
 // Handle cursor movement
@@ -106,7 +300,7 @@ func (g *Game) GetTimeString() string {
 // Get lives display
 func (g *Game) GetLivesDisplay() string {
 	display := ""
-	for i := range [3]struct{}{} {
+	for i := range MaxLives {
 		if i < g.Lives {
 			display += "❤️ "
 		} else {
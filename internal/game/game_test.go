@@ -0,0 +1,144 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Puzzle source handing out one fixed puzzle
+type fixedSource struct {
+	s     sudoku.Sudoku
+	taken bool
+}
+
+func (f *fixedSource) Take(d sudoku.Difficulty) (sudoku.Sudoku, bool) {
+	if f.taken {
+		return sudoku.Sudoku{}, false
+	}
+	f.taken = true
+	return f.s, true
+}
+
+func TestNewTakesFromPuzzles(t *testing.T) {
+	src := &fixedSource{s: sudoku.NewWithSeed(sudoku.Easy, 99)}
+	Puzzles = src
+	defer func() { Puzzles = nil }()
+
+	g := New(sudoku.Easy)
+	if g.Sudoku.Seed != 99 {
+		t.Errorf("got puzzle %d, want the one from the source", g.Sudoku.Seed)
+	}
+
+	// An empty source falls back to generating
+	if err := g.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Sudoku.Seed == 99 || g.Sudoku.Grid == src.s.Grid {
+		t.Error("reset reused the taken puzzle")
+	}
+}
+
+func TestResetFollowsVariant(t *testing.T) {
+	g, err := NewWithOptions(sudoku.Easy, sudoku.WithSeed(1), sudoku.WithWindows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Variant != sudoku.VariantHyper {
+		t.Fatalf("got variant %s, want Hyper", g.Variant)
+	}
+
+	// Another puzzle of the same kind until a different variant is picked
+	if err := g.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Sudoku.Rules.Windows {
+		t.Error("reset dropped the windows")
+	}
+	g.SwitchVariant()
+	if err := g.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Variant != sudoku.VariantClassic || g.Sudoku.Rules.Variant() != sudoku.VariantClassic {
+		t.Errorf("reset after switching gave a %s puzzle", g.Sudoku.Rules.Variant())
+	}
+}
+
+func TestNewWithOptionsReportsErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewWithOptions(sudoku.Easy, sudoku.WithContext(ctx)); err == nil {
+		t.Error("cancelled generation gave a game")
+	}
+}
+
+func TestPauseStopsClock(t *testing.T) {
+	g := NewWithSeed(sudoku.Easy, 1)
+	g.StartTime = time.Now().Add(-time.Minute)
+	g.Pause()
+
+	// An hour in the menu
+	g.StartTime = g.StartTime.Add(-time.Hour)
+	g.UpdateTime()
+	if g.Elapsed > 2*time.Minute {
+		t.Fatalf("paused clock ran to %s", g.Elapsed)
+	}
+
+	g.Resume()
+	g.UpdateTime()
+	if g.Elapsed < time.Minute || g.Elapsed > 2*time.Minute {
+		t.Errorf("resumed clock shows %s, want about a minute", g.Elapsed)
+	}
+}
+
+func TestDailySeed(t *testing.T) {
+	morning := time.Date(2026, 10, 17, 7, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC)
+	if DailySeed(morning) != 20261017 || DailySeed(evening) != DailySeed(morning) {
+		t.Errorf("got seeds %d and %d, want 20261017", DailySeed(morning), DailySeed(evening))
+	}
+	if DailySeed(morning.AddDate(0, 0, 1)) == DailySeed(morning) {
+		t.Error("the next day has the same seed")
+	}
+}
+
+func TestResetFollowsSize(t *testing.T) {
+	g, err := NewWithOptions(sudoku.Easy, sudoku.WithSeed(1), sudoku.WithDiagonals())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 9x9 to 12x12 drops the variant, which is only played on 9x9
+	g.SwitchSize()
+	if g.Size != 12 || g.Variant != sudoku.VariantClassic {
+		t.Fatalf("switched to size %d with variant %s", g.Size, g.Variant)
+	}
+	g.SwitchSize()
+	g.SwitchSize()
+	if err := g.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Sudoku.Size() != 4 || g.Sudoku.Rules.Diagonals {
+		t.Fatalf("reset gave a %dx%d %s puzzle", g.Sudoku.Size(), g.Sudoku.Size(), g.Sudoku.Rules.Variant())
+	}
+
+	// Digits above the board size cannot be entered
+	for i := range 4 {
+		for j := range 4 {
+			if g.Sudoku.Grid[i][j] == 0 {
+				g.Sudoku.CursorY, g.Sudoku.CursorX = i, j
+			}
+		}
+	}
+	if g.HandleNumberInput(5) {
+		t.Error("entered 5 on a 4x4 board")
+	}
+	if err := g.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Sudoku.Size() != 4 {
+		t.Errorf("second reset gave a %dx%d puzzle", g.Sudoku.Size(), g.Sudoku.Size())
+	}
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Points lost per hint shown
+const hintPenalty = 100
+
+// A hint points at a cell without showing its digit. Pressing the hint key
+// again applies it.
+type Hint struct {
+	Row   int
+	Col   int
+	Digit int    // Digit that belongs in the cell
+	Wrong bool   // The cell holds a wrong digit, applying the hint clears it
+	Text  string // Explanation shown to the player
+}
+
+// Show a hint for the current board, or apply the hint already shown
+func (g *Game) HandleHint() bool {
+	if g.Solved || g.GameOver {
+		return false
+	}
+
+	if g.Hint != nil {
+		return g.applyHint()
+	}
+
+	hint, ok := g.findHint()
+	if !ok {
+		return false
+	}
+	g.Hint = &hint
+	g.HintsUsed++
+	g.Sudoku.CursorY, g.Sudoku.CursorX = hint.Row, hint.Col
+	return true
+}
+
+// Check if a cell is the one the current hint points at
+func (g *Game) IsHinted(row, col int) bool {
+	return g.Hint != nil && g.Hint.Row == row && g.Hint.Col == col
+}
+
+// Work out the next hint. Wrong entries come first, since logic cannot be
+// trusted on a board that contradicts the solution.
+func (g *Game) findHint() (Hint, bool) {
+	s := &g.Sudoku
+	for i := range s.Grid {
+		for j, v := range s.Grid[i] {
+			if v != 0 && v != s.Solution[i][j] {
+				cell := sudoku.Cell{Row: i, Col: j}
+				return Hint{
+					Row:   i,
+					Col:   j,
+					Wrong: true,
+					Text:  fmt.Sprintf("%s does not fit the solution", cell),
+				}, true
+			}
+		}
+	}
+
+	if steps, ok := s.Rules.NextPlacement(s.Grid); ok {
+		p := steps[len(steps)-1].Placements[0]
+		return Hint{
+			Row:   p.Row,
+			Col:   p.Col,
+			Digit: p.Digit,
+			Text:  hintText(steps),
+		}, true
+	}
+
+	// Logic is stuck; point at the first empty cell instead
+	size := s.Size()
+	for i := range size {
+		for j, v := range s.Grid[i][:size] {
+			if v == 0 {
+				cell := sudoku.Cell{Row: i, Col: j}
+				return Hint{
+					Row:   i,
+					Col:   j,
+					Digit: s.Solution[i][j],
+					Text:  fmt.Sprintf("%s: no simple technique applies here", cell),
+				}, true
+			}
+		}
+	}
+	return Hint{}, false
+}
+
+// Describe the steps leading to a placement without naming the digit,
+// e.g. "r4c5: Hidden single in box 5 (after Pointing)"
+func hintText(steps []sudoku.Step) string {
+	last := steps[len(steps)-1]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", last.Placements[0].Cell, last.Technique)
+	if len(last.Units) > 0 {
+		fmt.Fprintf(&b, " in %s", last.Units[0])
+	}
+
+	var before []string
+	for _, step := range steps[:len(steps)-1] {
+		name := step.Technique.String()
+		if !slices.Contains(before, name) {
+			before = append(before, name)
+		}
+	}
+	if len(before) > 0 {
+		fmt.Fprintf(&b, " (after %s)", strings.Join(before, ", "))
+	}
+	return b.String()
+}
+
+// Place the hinted digit, or clear the wrong one
+func (g *Game) applyHint() bool {
+	hint := *g.Hint
+	g.Sudoku.CursorY, g.Sudoku.CursorX = hint.Row, hint.Col
+	if hint.Wrong {
+		return g.HandleClear()
+	}
+
+	notesMode := g.NotesMode
+	g.NotesMode = false
+	defer func() { g.NotesMode = notesMode }()
+	return g.HandleNumberInput(hint.Digit)
+}
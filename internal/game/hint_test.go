@@ -0,0 +1,84 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func TestHintPlacesDigitOnSecondPress(t *testing.T) {
+	g := newTestGame(t)
+
+	if !g.HandleHint() || g.Hint == nil {
+		t.Fatal("no hint shown")
+	}
+	hint := *g.Hint
+	if g.Sudoku.Grid[hint.Row][hint.Col] != 0 {
+		t.Fatalf("hint points at filled cell r%dc%d", hint.Row+1, hint.Col+1)
+	}
+	if !strings.Contains(hint.Text, "Hidden single") {
+		t.Errorf("easy puzzle hint %q, want a hidden single", hint.Text)
+	}
+	if g.Sudoku.CursorY != hint.Row || g.Sudoku.CursorX != hint.Col {
+		t.Error("cursor did not move to the hinted cell")
+	}
+
+	g.ToggleNotesMode()
+	if !g.HandleHint() {
+		t.Fatal("applying the hint failed")
+	}
+	if g.Sudoku.Grid[hint.Row][hint.Col] != g.Sudoku.Solution[hint.Row][hint.Col] {
+		t.Error("hint did not place the solution digit")
+	}
+	if g.Hint != nil || !g.NotesMode || g.HintsUsed != 1 || g.Lives != MaxLives {
+		t.Errorf("unexpected state after hint: hint %v, notes %v, hints %d, lives %d",
+			g.Hint, g.NotesMode, g.HintsUsed, g.Lives)
+	}
+}
+
+func TestHintFindsWrongEntry(t *testing.T) {
+	g := newTestGame(t)
+	row, col := g.Sudoku.CursorY, g.Sudoku.CursorX
+	g.HandleNumberInput(wrongDigit(g))
+	g.HandleMovement(4, 4)
+
+	g.HandleHint()
+	if g.Hint == nil || !g.Hint.Wrong || g.Hint.Row != row || g.Hint.Col != col {
+		t.Fatalf("hint %+v, want the wrong entry at r%dc%d", g.Hint, row+1, col+1)
+	}
+
+	g.HandleHint()
+	if g.Sudoku.Grid[row][col] != 0 {
+		t.Error("wrong entry not cleared")
+	}
+}
+
+func TestHintClearedByMove(t *testing.T) {
+	g := newTestGame(t)
+	g.HandleHint()
+	g.HandleMovement(1, 0)
+	if g.Hint == nil {
+		t.Fatal("moving the cursor dropped the hint")
+	}
+
+	g.Sudoku.CursorY, g.Sudoku.CursorX = g.Hint.Row, g.Hint.Col
+	g.HandleNumberInput(g.Sudoku.Solution[g.Hint.Row][g.Hint.Col])
+	if g.Hint != nil {
+		t.Error("hint still shown after the board changed")
+	}
+}
+
+func TestScore(t *testing.T) {
+	g := NewWithSeed(sudoku.Medium, 1)
+	if g.Score() != 0 {
+		t.Error("unsolved game has a score")
+	}
+
+	g.Solved = true
+	full := g.Score()
+	g.HintsUsed = 2
+	if g.Score() != full-2*hintPenalty {
+		t.Errorf("score %d with two hints, want %d", g.Score(), full-2*hintPenalty)
+	}
+}
@@ -0,0 +1,119 @@
+package game
+
+import "github.com/jensderond/sudoku-cli/pkg/sudoku"
+
+// A reversible change to the board. Placing a digit also clears it from
+// the notes of its peers, which are listed so undo can put the notes back.
+type Move struct {
+	Row      int
+	Col      int
+	OldValue int
+	NewValue int
+	OldNotes uint16        // Notes of the cell before the move
+	NewNotes uint16        // Notes of the cell after the move
+	Cleared  []sudoku.Cell // Peers that lost their note for NewValue
+	LostLife bool          // The move was a wrong entry and cost a life
+}
+
+// Move being made, with the notes before it to find the cleared peers
+type pendingMove struct {
+	Move
+	notes [sudoku.MaxSize][sudoku.MaxSize]uint16
+}
+
+// Undo and redo stacks. Undoing a wrong entry does not give the lost life
+// back, and redoing it does not cost another one: a mistake is paid for
+// exactly once.
+type History struct {
+	Undo []Move
+	Redo []Move
+}
+
+// Record a new move. Any undone moves can no longer be redone.
+func (h *History) record(m Move) {
+	h.Undo = append(h.Undo, m)
+	h.Redo = nil
+}
+
+// Snapshot the cell under the cursor and the notes before a change
+func (g *Game) beginMove() pendingMove {
+	row, col := g.Sudoku.CursorY, g.Sudoku.CursorX
+	return pendingMove{
+		Move: Move{
+			Row:      row,
+			Col:      col,
+			OldValue: g.Sudoku.GetCurrentValue(),
+			OldNotes: g.Sudoku.Notes[row][col],
+		},
+		notes: g.Sudoku.Notes,
+	}
+}
+
+// Complete a move started with beginMove and record it if anything changed
+func (g *Game) finishMove(p pendingMove) {
+	m := p.Move
+	m.NewValue = g.Sudoku.Grid[m.Row][m.Col]
+	m.NewNotes = g.Sudoku.Notes[m.Row][m.Col]
+	for i := range g.Sudoku.Notes {
+		for j := range g.Sudoku.Notes[i] {
+			if (i != m.Row || j != m.Col) && g.Sudoku.Notes[i][j] != p.notes[i][j] {
+				m.Cleared = append(m.Cleared, sudoku.Cell{Row: i, Col: j})
+			}
+		}
+	}
+	if m.NewValue != m.OldValue || m.NewNotes != m.OldNotes || len(m.Cleared) > 0 {
+		g.History.record(m)
+		g.Hint = nil // The board changed, so the hint may no longer apply
+	}
+}
+
+// Bit of a digit in a notes mask
+func noteBit(d int) uint16 {
+	return 1 << (d - 1)
+}
+
+// Undo the last move, moving the cursor to its cell
+func (g *Game) Undo() bool {
+	if g.Solved || g.GameOver || len(g.History.Undo) == 0 {
+		return false
+	}
+
+	m := g.History.Undo[len(g.History.Undo)-1]
+	g.History.Undo = g.History.Undo[:len(g.History.Undo)-1]
+
+	g.Sudoku.Grid[m.Row][m.Col] = m.OldValue
+	g.Sudoku.Notes[m.Row][m.Col] = m.OldNotes
+	for _, c := range m.Cleared {
+		g.Sudoku.Notes[c.Row][c.Col] |= noteBit(m.NewValue)
+	}
+	g.Sudoku.CursorY, g.Sudoku.CursorX = m.Row, m.Col
+
+	g.History.Redo = append(g.History.Redo, m)
+	g.Hint = nil
+	return true
+}
+
+// Redo the last undone move, moving the cursor to its cell
+func (g *Game) Redo() bool {
+	if g.Solved || g.GameOver || len(g.History.Redo) == 0 {
+		return false
+	}
+
+	m := g.History.Redo[len(g.History.Redo)-1]
+	g.History.Redo = g.History.Redo[:len(g.History.Redo)-1]
+
+	g.Sudoku.Grid[m.Row][m.Col] = m.NewValue
+	g.Sudoku.Notes[m.Row][m.Col] = m.NewNotes
+	for _, c := range m.Cleared {
+		g.Sudoku.Notes[c.Row][c.Col] &^= noteBit(m.NewValue)
+	}
+	g.Sudoku.CursorY, g.Sudoku.CursorX = m.Row, m.Col
+
+	g.History.Undo = append(g.History.Undo, m)
+	g.Hint = nil
+
+	if g.Sudoku.IsSolved() {
+		g.Solved = true
+	}
+	return true
+}
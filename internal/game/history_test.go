@@ -0,0 +1,137 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Helper: a game with the cursor on the first empty cell
+func newTestGame(t *testing.T) *Game {
+	t.Helper()
+	g := NewWithSeed(sudoku.Easy, 1)
+	for i := range 9 {
+		for j := range 9 {
+			if g.Sudoku.Grid[i][j] == 0 {
+				g.Sudoku.CursorY, g.Sudoku.CursorX = i, j
+				return g
+			}
+		}
+	}
+	t.Fatal("puzzle has no empty cells")
+	return nil
+}
+
+// Helper: a digit that is wrong for the cell under the cursor
+func wrongDigit(g *Game) int {
+	return g.Sudoku.Solution[g.Sudoku.CursorY][g.Sudoku.CursorX]%9 + 1
+}
+
+func TestUndoRedoDigit(t *testing.T) {
+	g := newTestGame(t)
+	row, col := g.Sudoku.CursorY, g.Sudoku.CursorX
+	answer := g.Sudoku.Solution[row][col]
+
+	g.HandleNumberInput(answer)
+	g.HandleMovement(1, 1)
+
+	if !g.Undo() {
+		t.Fatal("undo failed")
+	}
+	if g.Sudoku.Grid[row][col] != 0 {
+		t.Error("undo did not clear the digit")
+	}
+	if g.Sudoku.CursorY != row || g.Sudoku.CursorX != col {
+		t.Error("undo did not move the cursor back to the cell")
+	}
+
+	if !g.Redo() || g.Sudoku.Grid[row][col] != answer {
+		t.Error("redo did not restore the digit")
+	}
+	if g.Redo() {
+		t.Error("redo succeeded with nothing left to redo")
+	}
+}
+
+func TestUndoWrongEntryKeepsLifeLost(t *testing.T) {
+	g := newTestGame(t)
+
+	g.HandleNumberInput(wrongDigit(g))
+	if g.Lives != 2 {
+		t.Fatalf("wrong entry left %d lives, want 2", g.Lives)
+	}
+
+	g.Undo()
+	if g.Lives != 2 {
+		t.Errorf("undo gave the life back: %d lives", g.Lives)
+	}
+
+	g.Redo()
+	if g.Lives != 2 {
+		t.Errorf("redo cost another life: %d lives", g.Lives)
+	}
+}
+
+func TestUndoRestoresNotes(t *testing.T) {
+	g := newTestGame(t)
+	row, col := g.Sudoku.CursorY, g.Sudoku.CursorX
+	answer := g.Sudoku.Solution[row][col]
+
+	// Put a note for the answer in a peer cell of the same row
+	for j := range 9 {
+		if j != col && g.Sudoku.Grid[row][j] == 0 {
+			g.Sudoku.CursorX = j
+			g.ToggleNotesMode()
+			g.HandleNumberInput(answer)
+			g.ToggleNotesMode()
+			break
+		}
+	}
+	peer := g.Sudoku.CursorX
+	if !g.Sudoku.HasNote(row, peer, answer) {
+		t.Fatal("note was not added")
+	}
+
+	g.Sudoku.CursorX = col
+	g.HandleNumberInput(answer)
+	if g.Sudoku.HasNote(row, peer, answer) {
+		t.Fatal("placing the digit did not clear the peer note")
+	}
+	move := g.History.Undo[len(g.History.Undo)-1]
+	if len(move.Cleared) != 1 || move.Cleared[0] != (sudoku.Cell{Row: row, Col: peer}) {
+		t.Errorf("move cleared %v, want only the peer", move.Cleared)
+	}
+
+	g.Undo()
+	if !g.Sudoku.HasNote(row, peer, answer) {
+		t.Error("undo did not restore the peer note")
+	}
+
+	g.Undo()
+	if g.Sudoku.HasNote(row, peer, answer) {
+		t.Error("undo did not remove the note")
+	}
+
+	g.Redo()
+	g.Redo()
+	if g.Sudoku.HasNote(row, peer, answer) {
+		t.Error("redo did not clear the peer note again")
+	}
+}
+
+func TestNewMoveClearsRedo(t *testing.T) {
+	g := newTestGame(t)
+	answer := g.Sudoku.Solution[g.Sudoku.CursorY][g.Sudoku.CursorX]
+
+	g.HandleNumberInput(answer)
+	g.Undo()
+	g.HandleClear() // Nothing to clear, so nothing is recorded
+	if len(g.History.Redo) != 1 {
+		t.Fatal("a no-op clear discarded the redo stack")
+	}
+
+	g.HandleNumberInput(answer)
+	if len(g.History.Redo) != 0 {
+		t.Error("a new move kept the redo stack")
+	}
+}
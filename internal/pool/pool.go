@@ -0,0 +1,155 @@
+package pool
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Difficulties the pool keeps puzzles for
+var difficulties = []sudoku.Difficulty{sudoku.Easy, sudoku.Medium, sudoku.Hard, sudoku.Expert}
+
+// Pool keeps a few ready puzzles per difficulty so a new game can start
+// instantly. Background workers top it up whenever a puzzle is taken.
+type Pool struct {
+	size int // Puzzles to keep per difficulty
+
+	mu      sync.Mutex
+	puzzles map[sudoku.Difficulty][]sudoku.Sudoku
+	wake    chan struct{} // Signals the workers that the pool has room
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Puzzle source, replaced in tests
+	generate func(ctx context.Context, d sudoku.Difficulty) (sudoku.Sudoku, error)
+}
+
+// Create an empty pool holding up to size puzzles per difficulty. Call
+// Start to fill it.
+func New(size int) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		size:    size,
+		puzzles: make(map[sudoku.Difficulty][]sudoku.Sudoku),
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		generate: func(ctx context.Context, d sudoku.Difficulty) (sudoku.Sudoku, error) {
+			return sudoku.Generate(d, sudoku.WithContext(ctx))
+		},
+	}
+}
+
+// Start background workers that keep the pool full until Close
+func (p *Pool) Start(workers int) {
+	for range max(workers, 1) {
+		p.wg.Add(1)
+		go p.refill()
+	}
+}
+
+// Stop the workers and wait for them to finish. Puzzles already in the
+// pool can still be taken.
+func (p *Pool) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Take a ready puzzle, if there is one
+func (p *Pool) Take(d sudoku.Difficulty) (sudoku.Sudoku, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ready := p.puzzles[d]
+	if len(ready) == 0 {
+		return sudoku.Sudoku{}, false
+	}
+	s := ready[0]
+	p.puzzles[d] = ready[1:]
+	p.signal()
+	return s, true
+}
+
+// Add puzzles, such as ones saved from an earlier run. Puzzles beyond the
+// pool size are dropped.
+func (p *Pool) Add(d sudoku.Difficulty, puzzles ...sudoku.Sudoku) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	room := p.size - len(p.puzzles[d])
+	if room <= 0 {
+		return
+	}
+	if len(puzzles) > room {
+		puzzles = puzzles[:room]
+	}
+	p.puzzles[d] = append(p.puzzles[d], puzzles...)
+}
+
+// Count the ready puzzles of a difficulty
+func (p *Pool) Len(d sudoku.Difficulty) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.puzzles[d])
+}
+
+// Copy the ready puzzles, for saving them
+func (p *Pool) Puzzles() map[sudoku.Difficulty][]sudoku.Sudoku {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	puzzles := make(map[sudoku.Difficulty][]sudoku.Sudoku, len(p.puzzles))
+	for d, ready := range p.puzzles {
+		puzzles[d] = append([]sudoku.Sudoku(nil), ready...)
+	}
+	return puzzles
+}
+
+// Wake a waiting worker without blocking
+func (p *Pool) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Generate puzzles for the emptiest difficulty until the pool is full,
+// then sleep until a puzzle is taken
+func (p *Pool) refill() {
+	defer p.wg.Done()
+
+	for {
+		d, ok := p.neediest()
+		if !ok {
+			select {
+			case <-p.wake:
+				continue
+			case <-p.ctx.Done():
+				return
+			}
+		}
+
+		s, err := p.generate(p.ctx, d)
+		if err != nil {
+			return // Only fails once the pool is closed
+		}
+		p.Add(d, s)
+	}
+}
+
+// Find the difficulty with the fewest ready puzzles, if any has room
+func (p *Pool) neediest() (sudoku.Difficulty, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best, fewest := sudoku.Easy, p.size
+	for _, d := range difficulties {
+		if n := len(p.puzzles[d]); n < fewest {
+			best, fewest = d, n
+		}
+	}
+	return best, fewest < p.size
+}
@@ -0,0 +1,138 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Helper: a pool whose puzzles are numbered by their seed instead of
+// generated, so tests run fast and can spot duplicates
+func newTestPool(size int) (*Pool, *atomic.Int64) {
+	p := New(size)
+	var made atomic.Int64
+	p.generate = func(ctx context.Context, d sudoku.Difficulty) (sudoku.Sudoku, error) {
+		return sudoku.Sudoku{Seed: made.Add(1)}, ctx.Err()
+	}
+	return p, &made
+}
+
+// Helper: wait until every difficulty holds n puzzles
+func waitFull(t *testing.T, p *Pool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for _, d := range difficulties {
+		for p.Len(d) < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s has %d puzzles, want %d", d, p.Len(d), n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestPoolFillsAndRefills(t *testing.T) {
+	p, made := newTestPool(1)
+	p.Start(2)
+	defer p.Close()
+
+	waitFull(t, p, 1)
+	before := made.Load()
+	if _, ok := p.Take(sudoku.Expert); !ok {
+		t.Fatal("no puzzle in a full pool")
+	}
+
+	waitFull(t, p, 1)
+	if made.Load() <= before {
+		t.Error("taken puzzle was not replaced")
+	}
+}
+
+func TestPoolConcurrentTake(t *testing.T) {
+	p, made := newTestPool(3)
+	p.Start(4)
+	waitFull(t, p, 3)
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				d := difficulties[i%len(difficulties)]
+				s, ok := p.Take(d)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				if seen[s.Seed] {
+					t.Errorf("puzzle %d taken twice", s.Seed)
+				}
+				seen[s.Seed] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	p.Close()
+
+	for _, d := range difficulties {
+		if n := p.Len(d); n > 3 {
+			t.Errorf("%s holds %d puzzles, more than the pool size", d, n)
+		}
+	}
+	if len(seen) == 0 || int64(len(seen)) > made.Load() {
+		t.Errorf("took %d puzzles of %d made", len(seen), made.Load())
+	}
+}
+
+func TestPoolCloseStopsWorkers(t *testing.T) {
+	p := New(2)
+	started := make(chan struct{}, 4)
+	p.generate = func(ctx context.Context, d sudoku.Difficulty) (sudoku.Sudoku, error) {
+		started <- struct{}{}
+		<-ctx.Done() // Generation that only ends when cancelled
+		return sudoku.Sudoku{}, ctx.Err()
+	}
+	p.Start(3)
+	for range 3 {
+		<-started
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not stop the workers")
+	}
+
+	for _, d := range difficulties {
+		if p.Len(d) != 0 {
+			t.Errorf("cancelled generation added a puzzle to %s", d)
+		}
+	}
+}
+
+func TestPoolAddRespectsSize(t *testing.T) {
+	p, _ := newTestPool(2)
+	p.Add(sudoku.Hard, sudoku.Sudoku{Seed: 1}, sudoku.Sudoku{Seed: 2}, sudoku.Sudoku{Seed: 3})
+	if n := p.Len(sudoku.Hard); n != 2 {
+		t.Errorf("got %d puzzles, want 2", n)
+	}
+	if s, _ := p.Take(sudoku.Hard); s.Seed != 1 {
+		t.Errorf("took puzzle %d, want the oldest", s.Seed)
+	}
+	if _, ok := p.Take(sudoku.Easy); ok {
+		t.Error("took a puzzle from an empty difficulty")
+	}
+}
@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Outcome of one finished game
+type Result struct {
+	Difficulty sudoku.Difficulty `json:"difficulty"`
+	Size       int               `json:"size,omitempty"`    // Board size; missing in results from before sizes were kept
+	Variant    sudoku.Variant    `json:"variant,omitempty"` // Kind of rules played
+	Seed       int64             `json:"seed"`
+	Solved     bool              `json:"solved"`
+	ElapsedMs  int64             `json:"elapsed_ms"`
+	LivesLeft  int               `json:"lives_left"`
+	Hints      int               `json:"hints"`
+	Score      int               `json:"score"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
+
+// Build the result of a finished game
+func FromGame(g *game.Game) Result {
+	return Result{
+		Difficulty: g.Difficulty,
+		Size:       g.Sudoku.Size(),
+		Variant:    g.Sudoku.Rules.Variant(),
+		Seed:       g.Sudoku.Seed,
+		Solved:     g.Solved,
+		ElapsedMs:  g.Elapsed.Milliseconds(),
+		LivesLeft:  g.Lives,
+		Hints:      g.HintsUsed,
+		Score:      g.Score(),
+		FinishedAt: time.Now(),
+	}
+}
+
+func (r Result) Elapsed() time.Duration {
+	return time.Duration(r.ElapsedMs) * time.Millisecond
+}
+
+// Board and rules a game was played with. Statistics of different kinds
+// are kept apart, so a 4x4 win does not count towards 9x9 times.
+type Kind struct {
+	Size    int
+	Variant sudoku.Variant
+}
+
+// Classic 9x9 Sudoku
+var ClassicKind = Kind{Size: 9, Variant: sudoku.VariantClassic}
+
+func (k Kind) String() string {
+	if k.Variant != sudoku.VariantClassic {
+		return k.Variant.String()
+	}
+	return fmt.Sprintf("%dx%d", k.Size, k.Size)
+}
+
+// Kind of the game. Results recorded before sizes were kept are 9x9.
+func (r Result) Kind() Kind {
+	k := Kind{Size: r.Size, Variant: r.Variant}
+	if k.Size == 0 {
+		k.Size = 9
+	}
+	return k
+}
+
+// Every recorded result, oldest first
+type Log struct {
+	Results []Result `json:"results"`
+}
+
+func (l *Log) Add(r Result) {
+	l.Results = append(l.Results, r)
+}
+
+// Results for one kind and difficulty, oldest first
+func (l *Log) For(k Kind, d sudoku.Difficulty) []Result {
+	var results []Result
+	for _, r := range l.Results {
+		if r.Kind() == k && r.Difficulty == d {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Results for one kind over all difficulties, oldest first
+func (l *Log) OfKind(k Kind) []Result {
+	var results []Result
+	for _, r := range l.Results {
+		if r.Kind() == k {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Kinds other than classic 9x9 that have results: classic boards from
+// small to large, then the variants
+func (l *Log) OtherKinds() []Kind {
+	var kinds []Kind
+	for _, r := range l.Results {
+		if k := r.Kind(); k != ClassicKind && !slices.Contains(kinds, k) {
+			kinds = append(kinds, k)
+		}
+	}
+	slices.SortFunc(kinds, func(a, b Kind) int {
+		if a.Variant != b.Variant {
+			return cmp.Compare(a.Variant, b.Variant)
+		}
+		return cmp.Compare(a.Size, b.Size)
+	})
+	return kinds
+}
+
+// Aggregated statistics over a set of results
+type Summary struct {
+	Played        int
+	Won           int
+	BestTime      time.Duration // Fastest win
+	AverageTime   time.Duration // Average over wins
+	CurrentStreak int           // Wins since the last loss
+	LongestStreak int
+	BestScore     int
+	Hints         int // Hints used over all games
+}
+
+// Share of games won, from 0 to 1
+func (s Summary) WinRate() float64 {
+	if s.Played == 0 {
+		return 0
+	}
+	return float64(s.Won) / float64(s.Played)
+}
+
+// Summarize results given oldest first
+func Summarize(results []Result) Summary {
+	var s Summary
+	var total time.Duration
+
+	for _, r := range results {
+		s.Played++
+		s.Hints += r.Hints
+		if !r.Solved {
+			s.CurrentStreak = 0
+			continue
+		}
+
+		s.Won++
+		s.CurrentStreak++
+		s.LongestStreak = max(s.LongestStreak, s.CurrentStreak)
+		s.BestScore = max(s.BestScore, r.Score)
+
+		elapsed := r.Elapsed()
+		total += elapsed
+		if s.BestTime == 0 || elapsed < s.BestTime {
+			s.BestTime = elapsed
+		}
+	}
+
+	if s.Won > 0 {
+		s.AverageTime = total / time.Duration(s.Won)
+	}
+	return s
+}
+
+// Write a table with a row per difficulty of classic 9x9 and their total,
+// then a row per difficulty played of every other kind. Other kinds stay
+// out of the total, as their times and streaks do not compare.
+func WriteTable(w io.Writer, l *Log) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Difficulty\tPlayed\tWon\tWin %\tBest\tAverage\tStreak\tLongest\tBest score\tHints\t")
+
+	for d := sudoku.Easy; d <= sudoku.Expert; d++ {
+		writeRow(tw, d.String(), Summarize(l.For(ClassicKind, d)))
+	}
+	writeRow(tw, "All", Summarize(l.OfKind(ClassicKind)))
+	for _, k := range l.OtherKinds() {
+		for d := sudoku.Easy; d <= sudoku.Expert; d++ {
+			if results := l.For(k, d); len(results) > 0 {
+				writeRow(tw, fmt.Sprintf("%s (%s)", d, k), Summarize(results))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeRow(w io.Writer, label string, s Summary) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%.0f%%\t%s\t%s\t%d\t%d\t%d\t%d\t\n",
+		label, s.Played, s.Won, s.WinRate()*100,
+		formatTime(s.BestTime), formatTime(s.AverageTime),
+		s.CurrentStreak, s.LongestStreak, s.BestScore, s.Hints)
+}
+
+// Format a duration like the in-game timer, or a dash when there is none
+func formatTime(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
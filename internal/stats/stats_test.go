@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func result(d sudoku.Difficulty, solved bool, seconds int) Result {
+	return Result{Difficulty: d, Solved: solved, ElapsedMs: int64(seconds) * 1000}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []Result{
+		result(sudoku.Easy, true, 300),
+		result(sudoku.Easy, true, 200),
+		result(sudoku.Easy, true, 400),
+		result(sudoku.Easy, false, 50),
+		result(sudoku.Easy, true, 100),
+	}
+
+	results[1].Score, results[2].Score = 1500, 900
+	results[3].Hints, results[4].Hints = 2, 1
+
+	s := Summarize(results)
+	if s.Played != 5 || s.Won != 4 {
+		t.Errorf("played %d won %d, want 5 and 4", s.Played, s.Won)
+	}
+	if s.WinRate() != 0.8 {
+		t.Errorf("win rate %v, want 0.8", s.WinRate())
+	}
+	if s.BestTime != 100*time.Second {
+		t.Errorf("best time %v, want 100s", s.BestTime)
+	}
+	if s.AverageTime != 250*time.Second {
+		t.Errorf("average time %v, want 250s", s.AverageTime)
+	}
+	if s.CurrentStreak != 1 || s.LongestStreak != 3 {
+		t.Errorf("streaks %d/%d, want 1/3", s.CurrentStreak, s.LongestStreak)
+	}
+	if s.BestScore != 1500 || s.Hints != 3 {
+		t.Errorf("best score %d hints %d, want 1500 and 3", s.BestScore, s.Hints)
+	}
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	s := Summarize(nil)
+	if s != (Summary{}) || s.WinRate() != 0 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	l := &Log{}
+	l.Add(result(sudoku.Hard, true, 754))
+	l.Add(result(sudoku.Easy, false, 10))
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, l); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d lines, want header, 4 difficulties and a total:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[3], "Hard") || !strings.Contains(lines[3], "12:34") {
+		t.Errorf("Hard row missing best time: %q", lines[3])
+	}
+	if !strings.Contains(lines[5], "50%") {
+		t.Errorf("total row missing win rate: %q", lines[5])
+	}
+}
+
+func TestWriteTableKeepsKindsApart(t *testing.T) {
+	l := &Log{}
+	l.Add(result(sudoku.Easy, true, 600))
+	kids := result(sudoku.Easy, true, 30)
+	kids.Size = 4
+	l.Add(kids)
+	killer := result(sudoku.Hard, false, 900)
+	killer.Size, killer.Variant = 9, sudoku.VariantKiller
+	l.Add(killer)
+
+	if s := Summarize(l.For(ClassicKind, sudoku.Easy)); s.Played != 1 || s.BestTime != 600*time.Second {
+		t.Errorf("classic Easy summary %+v mixes in other kinds", s)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, l); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("got %d lines, want header, 4 difficulties, a total and 2 other kinds:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[1], "10:00") {
+		t.Errorf("Easy row should keep the 9x9 time: %q", lines[1])
+	}
+	if !strings.Contains(lines[5], "All") || !strings.Contains(lines[5], "100%") || !strings.Contains(lines[5], "10:00") {
+		t.Errorf("total row should only count 9x9 games: %q", lines[5])
+	}
+	if !strings.Contains(lines[6], "Easy (4x4)") || !strings.Contains(lines[6], "00:30") {
+		t.Errorf("4x4 row missing: %q", lines[6])
+	}
+	if !strings.Contains(lines[7], "Hard (Killer)") {
+		t.Errorf("killer row missing: %q", lines[7])
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Directory name used under the XDG base directories
+const appName = "sudoku-cli"
+
+// Directory for state that should survive restarts but is not worth
+// backing up, such as a game in progress. Follows XDG_STATE_HOME.
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// Directory for user data worth keeping, such as statistics. Follows
+// XDG_DATA_HOME.
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// Directory for preferences, such as the settings picked in the menu.
+// Follows XDG_CONFIG_HOME.
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// Directory for data that can be thrown away, such as pre-generated
+// puzzles. Follows XDG_CACHE_HOME.
+func CacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// Get the XDG directory from env, falling back to a path under the home
+// directory
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, appName), nil
+}
+
+// Write a file atomically so a crash never leaves a half-written file
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
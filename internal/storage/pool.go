@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jensderond/sudoku-cli/internal/pool"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Version of the puzzle pool file format. Bump it when the format changes.
+const PoolVersion = 1
+
+// Unused pool puzzles as written to disk
+type poolFile struct {
+	Version int            `json:"version"`
+	Puzzles []pooledPuzzle `json:"puzzles"`
+}
+
+type pooledPuzzle struct {
+	Difficulty sudoku.Difficulty `json:"difficulty"`
+	Seed       int64             `json:"seed"`
+	Givens     string            `json:"givens"`
+}
+
+// Location of the puzzle pool file
+func PoolPath() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pool.json"), nil
+}
+
+// Save the puzzles left in a pool for the next run
+func SavePool(p *pool.Pool) error {
+	path, err := PoolPath()
+	if err != nil {
+		return err
+	}
+
+	f := poolFile{Version: PoolVersion, Puzzles: []pooledPuzzle{}}
+	for d, puzzles := range p.Puzzles() {
+		for _, s := range puzzles {
+			f.Puzzles = append(f.Puzzles, pooledPuzzle{d, s.Seed, s.Grid.String()})
+		}
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// Move the puzzles saved by SavePool into a pool. The file is removed so
+// the same puzzles are not handed out twice. A missing file adds nothing.
+func LoadPool(p *pool.Pool) error {
+	path, err := PoolPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	var f poolFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if f.Version != PoolVersion {
+		return fmt.Errorf("reading %s: unsupported pool version %d", path, f.Version)
+	}
+
+	for _, pp := range f.Puzzles {
+		s, err := sudoku.Import(pp.Givens, sudoku.FormatLine)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		s.Seed = pp.Seed
+		p.Add(pp.Difficulty, s)
+	}
+	return nil
+}
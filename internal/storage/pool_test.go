@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jensderond/sudoku-cli/internal/pool"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func TestSaveAndLoadPool(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	p := pool.New(2)
+	hard := sudoku.NewWithSeed(sudoku.Hard, 7)
+	p.Add(sudoku.Hard, hard)
+	p.Add(sudoku.Easy, sudoku.NewWithSeed(sudoku.Easy, 8))
+	if err := SavePool(p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := pool.New(2)
+	if err := LoadPool(loaded); err != nil {
+		t.Fatal(err)
+	}
+	s, ok := loaded.Take(sudoku.Hard)
+	if !ok || !reflect.DeepEqual(s, hard) {
+		t.Errorf("loaded puzzle differs from the saved one")
+	}
+	if loaded.Len(sudoku.Easy) != 1 || loaded.Len(sudoku.Expert) != 0 {
+		t.Error("puzzles loaded into the wrong difficulty")
+	}
+
+	// The file is used up by loading it
+	again := pool.New(2)
+	if err := LoadPool(again); err != nil {
+		t.Fatal(err)
+	}
+	if again.Len(sudoku.Easy) != 0 {
+		t.Error("saved puzzles handed out twice")
+	}
+}
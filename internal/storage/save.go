@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Version of the save file format. Bump it when the format changes.
+// Version 1 saves stored whole notes boards per move; they still load, but
+// without their undo history.
+const SaveVersion = 2
+
+// Returned by LoadGame when there is no saved game
+var ErrNoSave = errors.New("no saved game")
+
+// Saved game as written to disk
+type saveFile struct {
+	Version    int                                    `json:"version"`
+	SavedAt    time.Time                              `json:"saved_at"`
+	Difficulty sudoku.Difficulty                      `json:"difficulty"`
+	Seed       int64                                  `json:"seed"`
+	Grid       sudoku.Grid                            `json:"grid"`
+	Solution   sudoku.Grid                            `json:"solution"`
+	Initial    [sudoku.MaxSize][sudoku.MaxSize]bool   `json:"initial"`
+	Notes      [sudoku.MaxSize][sudoku.MaxSize]uint16 `json:"notes"`
+	Size       int                                    `json:"size,omitempty"`    // Set for boards other than 9x9
+	Regions    *sudoku.Regions                        `json:"regions,omitempty"` // Set for jigsaw puzzles
+	Cages      []sudoku.Cage                          `json:"cages,omitempty"`   // Set for killer puzzles
+	Diagonals  bool                                   `json:"diagonals,omitempty"`
+	Windows    bool                                   `json:"windows,omitempty"`
+	CursorX    int                                    `json:"cursor_x"`
+	CursorY    int                                    `json:"cursor_y"`
+	Lives      int                                    `json:"lives"`
+	ElapsedMs  int64                                  `json:"elapsed_ms"`
+	NotesMode  bool                                   `json:"notes_mode"`
+	HintsUsed  int                                    `json:"hints_used"`
+	Undo       []savedMove                            `json:"undo"`
+	Redo       []savedMove                            `json:"redo"`
+}
+
+type savedMove struct {
+	Row      int           `json:"row"`
+	Col      int           `json:"col"`
+	OldValue int           `json:"old_value"`
+	NewValue int           `json:"new_value"`
+	OldNotes uint16        `json:"old_cell_notes"`
+	NewNotes uint16        `json:"new_cell_notes"`
+	Cleared  []sudoku.Cell `json:"cleared,omitempty"`
+	LostLife bool          `json:"lost_life"`
+}
+
+// Location of the save file
+func SavePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "save.json"), nil
+}
+
+// Check if a saved game exists
+func HasSave() bool {
+	path, err := SavePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Save a game in progress
+func SaveGame(g *game.Game) error {
+	path, err := SavePath()
+	if err != nil {
+		return err
+	}
+
+	g.UpdateTime() // Keeps the time of a paused game
+	var size int
+	if n := g.Sudoku.Size(); n != 9 {
+		size = n
+	}
+	f := saveFile{
+		Version:    SaveVersion,
+		SavedAt:    time.Now(),
+		Difficulty: g.Difficulty,
+		Seed:       g.Sudoku.Seed,
+		Grid:       g.Sudoku.Grid,
+		Solution:   g.Sudoku.Solution,
+		Initial:    g.Sudoku.Initial,
+		Notes:      g.Sudoku.Notes,
+		Size:       size,
+		Regions:    g.Sudoku.Rules.Regions,
+		Cages:      g.Sudoku.Rules.Cages,
+		Diagonals:  g.Sudoku.Rules.Diagonals,
+		Windows:    g.Sudoku.Rules.Windows,
+		CursorX:    g.Sudoku.CursorX,
+		CursorY:    g.Sudoku.CursorY,
+		Lives:      g.Lives,
+		ElapsedMs:  g.Elapsed.Milliseconds(),
+		NotesMode:  g.NotesMode,
+		HintsUsed:  g.HintsUsed,
+		Undo:       toSavedMoves(g.History.Undo),
+		Redo:       toSavedMoves(g.History.Redo),
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// Load the saved game. The clock resumes from the saved elapsed time.
+func LoadGame() (*game.Game, error) {
+	path, err := SavePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSave
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f saveFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	switch f.Version {
+	case SaveVersion:
+	case 1:
+		f.Undo, f.Redo = nil, nil // Moves in the old format cannot be read
+	default:
+		return nil, fmt.Errorf("reading %s: unsupported save version %d", path, f.Version)
+	}
+
+	rules := sudoku.Rules{}
+	if f.Size != 0 {
+		if rules, err = sudoku.SizedRules(f.Size); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	rules.Regions = f.Regions
+	rules.Cages = f.Cages
+	rules.Diagonals = f.Diagonals
+	rules.Windows = f.Windows
+	if err := checkSave(&f, rules); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	elapsed := time.Duration(f.ElapsedMs) * time.Millisecond
+	g := &game.Game{
+		Sudoku: sudoku.Sudoku{
+			Grid:     f.Grid,
+			Solution: f.Solution,
+			Initial:  f.Initial,
+			Notes:    f.Notes,
+			Rules:    rules,
+			Seed:     f.Seed,
+			CursorX:  f.CursorX,
+			CursorY:  f.CursorY,
+		},
+		Difficulty: f.Difficulty,
+		Lives:      f.Lives,
+		StartTime:  time.Now().Add(-elapsed),
+		Elapsed:    elapsed,
+		NotesMode:  f.NotesMode,
+		HintsUsed:  f.HintsUsed,
+		History: game.History{
+			Undo: fromSavedMoves(f.Undo),
+			Redo: fromSavedMoves(f.Redo),
+		},
+	}
+	g.Variant = g.Sudoku.Rules.Variant()
+	g.Size = g.Sudoku.Size()
+	g.Solved = g.Sudoku.IsSolved()
+	g.GameOver = g.Lives <= 0
+	return g, nil
+}
+
+// Check that a save describes a game that can be played, so a corrupt or
+// hand-edited file fails to load instead of panicking during play. The
+// rules check also covers jigsaw regions: nine of nine cells, numbered 0-8.
+func checkSave(f *saveFile, rules sudoku.Rules) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	size := rules.Size()
+	onBoard := func(row, col int) bool {
+		return row >= 0 && row < size && col >= 0 && col < size
+	}
+	if !onBoard(f.CursorY, f.CursorX) {
+		return fmt.Errorf("cursor at row %d, column %d is off the %dx%d board", f.CursorY+1, f.CursorX+1, size, size)
+	}
+	if f.Lives < 0 || f.Lives > game.MaxLives {
+		return fmt.Errorf("%d lives, want 0 to %d", f.Lives, game.MaxLives)
+	}
+	for i := range sudoku.MaxSize {
+		for j := range sudoku.MaxSize {
+			for _, v := range []int{f.Grid[i][j], f.Solution[i][j]} {
+				if v < 0 || v > size || (v != 0 && !onBoard(i, j)) {
+					return fmt.Errorf("digit %d at %s does not fit the %dx%d board", v, sudoku.Cell{Row: i, Col: j}, size, size)
+				}
+			}
+		}
+	}
+	for _, m := range append(f.Undo, f.Redo...) {
+		if !onBoard(m.Row, m.Col) {
+			return fmt.Errorf("move at row %d, column %d is off the %dx%d board", m.Row+1, m.Col+1, size, size)
+		}
+		if m.NewValue < 0 || m.NewValue > size || (len(m.Cleared) > 0 && m.NewValue == 0) {
+			return fmt.Errorf("move at %s places digit %d", sudoku.Cell{Row: m.Row, Col: m.Col}, m.NewValue)
+		}
+		for _, c := range m.Cleared {
+			if !onBoard(c.Row, c.Col) {
+				return fmt.Errorf("move at %s clears notes off the board at %s", sudoku.Cell{Row: m.Row, Col: m.Col}, c)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete the saved game, if any
+func RemoveSave() error {
+	path, err := SavePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func toSavedMoves(moves []game.Move) []savedMove {
+	saved := make([]savedMove, len(moves))
+	for i, m := range moves {
+		saved[i] = savedMove(m)
+	}
+	return saved
+}
+
+func fromSavedMoves(saved []savedMove) []game.Move {
+	moves := make([]game.Move, len(saved))
+	for i, m := range saved {
+		moves[i] = game.Move(m)
+	}
+	return moves
+}
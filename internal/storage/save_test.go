@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func TestSaveAndLoadGame(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	g := game.NewWithSeed(sudoku.Medium, 42)
+	for i := range 9 {
+		for j := range 9 {
+			if g.Sudoku.Grid[i][j] == 0 {
+				g.Sudoku.CursorY, g.Sudoku.CursorX = i, j
+			}
+		}
+	}
+	g.ToggleNotesMode()
+	g.HandleNumberInput(3)
+	g.ToggleNotesMode()
+	g.HandleNumberInput(g.Sudoku.Solution[g.Sudoku.CursorY][g.Sudoku.CursorX]%9 + 1)
+	g.Undo()
+	g.HintsUsed = 2
+	g.StartTime = time.Now().Add(-90 * time.Second)
+
+	if err := SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	if !HasSave() {
+		t.Fatal("save file not found after saving")
+	}
+
+	loaded, err := LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Sudoku.Grid != g.Sudoku.Grid || loaded.Sudoku.Solution != g.Sudoku.Solution ||
+		loaded.Sudoku.Initial != g.Sudoku.Initial || loaded.Sudoku.Notes != g.Sudoku.Notes {
+		t.Error("grids differ after loading")
+	}
+	if loaded.Sudoku.CursorX != g.Sudoku.CursorX || loaded.Sudoku.CursorY != g.Sudoku.CursorY {
+		t.Error("cursor differs after loading")
+	}
+	if loaded.Lives != 2 || loaded.Difficulty != sudoku.Medium || loaded.Sudoku.Seed != 42 || loaded.HintsUsed != 2 {
+		t.Errorf("game state differs after loading: %+v", loaded)
+	}
+	if loaded.Elapsed < 90*time.Second || loaded.Elapsed > 91*time.Second {
+		t.Errorf("elapsed time %v, want about 90s", loaded.Elapsed)
+	}
+	if len(loaded.History.Undo) != 1 || len(loaded.History.Redo) != 1 {
+		t.Errorf("history differs after loading: %d undo, %d redo", len(loaded.History.Undo), len(loaded.History.Redo))
+	}
+
+	// The restored history must still work
+	if !loaded.Redo() || loaded.Lives != 2 {
+		t.Error("redo after loading failed")
+	}
+}
+
+func TestSavePausedGame(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	g := game.NewWithSeed(sudoku.Easy, 1)
+	g.StartTime = time.Now().Add(-30 * time.Second)
+	g.Pause()
+	g.StartTime = g.StartTime.Add(-time.Hour) // An hour in the menu
+
+	if err := SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Elapsed < 30*time.Second || loaded.Elapsed > 31*time.Second {
+		t.Errorf("elapsed time %v, want about 30s", loaded.Elapsed)
+	}
+}
+
+func TestSaveAndLoadRules(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	g, err := game.NewWithOptions(sudoku.Easy, sudoku.WithSeed(3), sudoku.WithJigsaw(), sudoku.WithKiller())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Sudoku.Rules.Jigsaw() || *loaded.Sudoku.Rules.Regions != *g.Sudoku.Rules.Regions {
+		t.Error("regions differ after loading")
+	}
+	if !reflect.DeepEqual(loaded.Sudoku.Rules.Cages, g.Sudoku.Rules.Cages) {
+		t.Error("cages differ after loading")
+	}
+
+	g, err = game.NewWithOptions(sudoku.Easy, sudoku.WithSeed(3), sudoku.WithDiagonals(), sudoku.WithWindows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Sudoku.Rules.Diagonals || !loaded.Sudoku.Rules.Windows || loaded.Variant != sudoku.VariantX {
+		t.Error("diagonals, windows or variant differ after loading")
+	}
+
+	g, err = game.NewWithOptions(sudoku.Easy, sudoku.WithSeed(3), sudoku.WithSize(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Sudoku.Size() != 6 || loaded.Size != 6 || loaded.Sudoku.Grid != g.Sudoku.Grid {
+		t.Error("6x6 board differs after loading")
+	}
+}
+
+func TestLoadGameWithoutSave(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if _, err := LoadGame(); !errors.Is(err, ErrNoSave) {
+		t.Errorf("got %v, want ErrNoSave", err)
+	}
+	if err := RemoveSave(); err != nil {
+		t.Errorf("removing a missing save: %v", err)
+	}
+}
+
+func TestLoadGameRejectsOtherVersions(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	path, err := SavePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(path, []byte(`{"version": 999}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadGame(); err == nil {
+		t.Error("expected an error for an unknown save version")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("unreadable save file should be left alone")
+	}
+}
+
+func TestLoadVersionOneSave(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	g := game.NewWithSeed(sudoku.Easy, 1)
+	for i := range 9 {
+		for j := range 9 {
+			if g.Sudoku.Grid[i][j] == 0 {
+				g.Sudoku.CursorY, g.Sudoku.CursorX = i, j
+			}
+		}
+	}
+	g.HandleNumberInput(g.Sudoku.Solution[g.Sudoku.CursorY][g.Sudoku.CursorX])
+	if err := SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	path, err := SavePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f saveFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	f.Version = 1
+	if data, err = json.Marshal(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(path, data); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Sudoku.Grid != g.Sudoku.Grid {
+		t.Error("grid differs after loading a version 1 save")
+	}
+	if len(loaded.History.Undo) != 0 {
+		t.Errorf("version 1 history kept: %d undo moves", len(loaded.History.Undo))
+	}
+}
+
+func TestLoadGameRejectsCorruptSaves(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path, err := SavePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jigsaw, err := game.NewWithOptions(sudoku.Easy, sudoku.WithSeed(3), sudoku.WithJigsaw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		g       *game.Game
+		corrupt func(f *saveFile)
+	}{
+		{"cursor", game.NewWithSeed(sudoku.Easy, 1), func(f *saveFile) { f.CursorX = 9 }},
+		{"lives", game.NewWithSeed(sudoku.Easy, 1), func(f *saveFile) { f.Lives = 7 }},
+		{"digit", game.NewWithSeed(sudoku.Easy, 1), func(f *saveFile) { f.Grid[0][12] = 4 }},
+		{"size", game.NewWithSeed(sudoku.Easy, 1), func(f *saveFile) { f.Size = 10 }},
+		{"region", jigsaw, func(f *saveFile) { f.Regions[0][0] = 12 }},
+		{"move", game.NewWithSeed(sudoku.Easy, 1), func(f *saveFile) { f.Undo = []savedMove{{Row: -1}} }},
+		{"cleared", game.NewWithSeed(sudoku.Easy, 1), func(f *saveFile) {
+			f.Undo = []savedMove{{NewValue: 1, Cleared: []sudoku.Cell{{Row: 0, Col: 9}}}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SaveGame(tt.g); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var f saveFile
+			if err := json.Unmarshal(data, &f); err != nil {
+				t.Fatal(err)
+			}
+			tt.corrupt(&f)
+			if data, err = json.Marshal(f); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeFile(path, data); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadGame(); err == nil {
+				t.Error("corrupt save loaded")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Version of the settings file format. Bump it when the format changes.
+const SettingsVersion = 1
+
+// Preferences for new games started from the menu
+type Settings struct {
+	Size     int             // Board size of classic games
+	Symmetry sudoku.Symmetry // Pattern of the givens
+}
+
+// Settings used until others are saved
+func DefaultSettings() Settings {
+	return Settings{Size: 9, Symmetry: sudoku.SymmetryRotate180}
+}
+
+// Settings as written to disk
+type settingsFile struct {
+	Version  int    `json:"version"`
+	Size     int    `json:"size"`
+	Symmetry string `json:"symmetry"`
+}
+
+// Location of the settings file
+func SettingsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// Load the saved settings. A missing file gives the defaults.
+func LoadSettings() (Settings, error) {
+	path, err := SettingsPath()
+	if err != nil {
+		return DefaultSettings(), err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultSettings(), nil
+	}
+	if err != nil {
+		return DefaultSettings(), err
+	}
+
+	var f settingsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return DefaultSettings(), fmt.Errorf("reading %s: %w", path, err)
+	}
+	if f.Version != SettingsVersion {
+		return DefaultSettings(), fmt.Errorf("reading %s: unsupported settings version %d", path, f.Version)
+	}
+	if _, err := sudoku.BoxShapeFor(f.Size); err != nil {
+		return DefaultSettings(), fmt.Errorf("reading %s: %w", path, err)
+	}
+	symmetry, err := sudoku.ParseSymmetry(f.Symmetry)
+	if err != nil {
+		return DefaultSettings(), fmt.Errorf("reading %s: %w", path, err)
+	}
+	return Settings{Size: f.Size, Symmetry: symmetry}, nil
+}
+
+// Write the settings
+func SaveSettings(s Settings) error {
+	path, err := SettingsPath()
+	if err != nil {
+		return err
+	}
+
+	f := settingsFile{Version: SettingsVersion, Size: s.Size, Symmetry: s.Symmetry.String()}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
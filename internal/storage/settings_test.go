@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func TestSaveAndLoadSettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	s, err := LoadSettings()
+	if err != nil || s != DefaultSettings() {
+		t.Fatalf("fresh settings: %+v, %v", s, err)
+	}
+
+	want := Settings{Size: 6, Symmetry: sudoku.SymmetryMirror}
+	if err := SaveSettings(want); err != nil {
+		t.Fatal(err)
+	}
+	s, err = LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+}
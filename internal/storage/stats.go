@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jensderond/sudoku-cli/internal/stats"
+)
+
+// Version of the statistics file format. Bump it when the format changes.
+const StatsVersion = 1
+
+// Statistics as written to disk
+type statsFile struct {
+	Version int `json:"version"`
+	stats.Log
+}
+
+// Location of the statistics file
+func StatsPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stats.json"), nil
+}
+
+// Load all recorded results. A missing file is an empty log.
+func LoadStats() (*stats.Log, error) {
+	path, err := StatsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &stats.Log{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f statsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if f.Version != StatsVersion {
+		return nil, fmt.Errorf("reading %s: unsupported stats version %d", path, f.Version)
+	}
+	return &f.Log, nil
+}
+
+// Write all results
+func SaveStats(l *stats.Log) error {
+	path, err := StatsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(statsFile{Version: StatsVersion, Log: *l}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// Add a finished game to the statistics file
+func RecordResult(r stats.Result) error {
+	l, err := LoadStats()
+	if err != nil {
+		return err
+	}
+	l.Add(r)
+	return SaveStats(l)
+}
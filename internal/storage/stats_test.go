@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/jensderond/sudoku-cli/internal/stats"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func TestRecordResult(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	l, err := LoadStats()
+	if err != nil || len(l.Results) != 0 {
+		t.Fatalf("fresh stats: %v, %v", l, err)
+	}
+
+	for _, solved := range []bool{true, false} {
+		if err := RecordResult(stats.Result{Difficulty: sudoku.Hard, Solved: solved}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err = LoadStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Results) != 2 || !l.Results[0].Solved || l.Results[1].Solved {
+		t.Errorf("unexpected results %+v", l.Results)
+	}
+}
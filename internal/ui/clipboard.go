@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Copy text to the system clipboard with an OSC52 escape sequence, which
+// the terminal handles, so it also works over SSH. The sequence goes to
+// stderr to stay out of the way of the renderer on stdout.
+func copyToClipboard(text string) error {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
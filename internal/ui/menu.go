@@ -0,0 +1,407 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/internal/storage"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+// Screens the UI can show
+type screen int
+
+const (
+	screenGame screen = iota
+	screenMenu
+	screenDifficulty // Picking the difficulty of a new game
+	screenVariant    // Picking the variant of a new game
+	screenLoad       // Typing the path of a puzzle file
+	screenSettings
+	screenStats
+)
+
+// What choosing a menu entry does
+type menuAction int
+
+const (
+	actionContinue menuAction = iota
+	actionNew
+	actionDaily
+	actionLoad
+	actionStats
+	actionSettings
+	actionQuit
+	actionPick     // Pick the difficulty or variant in value
+	actionSize     // Switch the board size setting
+	actionSymmetry // Switch the symmetry setting
+)
+
+// Entry of the menu, or of a list of choices reached from it
+type menuItem struct {
+	title  string
+	desc   string
+	action menuAction
+	value  int // Difficulty or variant picked by actionPick
+}
+
+func (i menuItem) Title() string       { return i.title }
+func (i menuItem) Description() string { return i.desc }
+func (i menuItem) FilterValue() string { return i.title }
+
+// Keys shared by the menu lists
+var (
+	selectKey = key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("enter", "select"),
+	)
+	backKey = key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back"),
+	)
+)
+
+// Hardest techniques each difficulty calls for
+var difficultyDescriptions = map[sudoku.Difficulty]string{
+	sudoku.Easy:   "Hidden singles are enough",
+	sudoku.Medium: "Naked singles and pointing",
+	sudoku.Hard:   "Pairs and triples",
+	sudoku.Expert: "X-Wings, Swordfish and XY-Wings",
+}
+
+// What each variant adds to the classic rules
+var variantDescriptions = map[sudoku.Variant]string{
+	sudoku.VariantJigsaw: "Irregular regions instead of boxes",
+	sudoku.VariantKiller: "Cages whose digits add up to a sum, few givens",
+	sudoku.VariantX:      "Both main diagonals also hold every digit",
+	sudoku.VariantHyper:  "Four extra 3x3 windows also hold every digit",
+}
+
+// Build a list for the menu or one of its choices
+func (m *Model) newList(title string, items []list.Item, extra ...key.Binding) list.Model {
+	delegate := list.NewDefaultDelegate()
+	l := list.New(items, delegate, m.width, m.listHeight())
+	l.Title = title
+	l.Styles.Title = TitleStyle
+	l.SetFilteringEnabled(false)
+	l.SetShowStatusBar(false)
+	l.DisableQuitKeybindings()
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return append([]key.Binding{selectKey}, extra...)
+	}
+	return l
+}
+
+// Height left for a list, keeping room for a message below it
+func (m *Model) listHeight() int {
+	return max(m.height-2, 10)
+}
+
+// Show the main menu. Continue is offered when there is a game to go back
+// to, in memory or saved.
+func (m *Model) openMenu() {
+	var items []list.Item
+	if m.inProgress() || storage.HasSave() {
+		items = append(items, menuItem{title: "Continue", desc: "Resume the game in progress", action: actionContinue})
+	}
+	items = append(items,
+		menuItem{title: "New Game", desc: "Pick a difficulty and a variant", action: actionNew},
+		menuItem{title: "Daily Puzzle", desc: "Today's puzzle, the same for every player", action: actionDaily},
+		menuItem{title: "Load from File", desc: "Play a .sdk, .sdm, .ss, line or JSON puzzle", action: actionLoad},
+		menuItem{title: "Statistics", desc: "Results of finished games", action: actionStats},
+		menuItem{title: "Settings", desc: "Board size and pattern of the givens", action: actionSettings},
+		menuItem{title: "Quit", desc: "Save the game in progress and quit", action: actionQuit},
+	)
+
+	extra := []key.Binding{m.keys.Quit}
+	if m.inProgress() {
+		extra = append(extra, backKey)
+	}
+	m.menu = m.newList("🎮 SUDOKU", items, extra...)
+	m.screen = screenMenu
+}
+
+// Check if there is an unfinished game in memory
+func (m *Model) inProgress() bool {
+	return m.Game != nil && !m.Game.Finished()
+}
+
+// Leave the game for the menu, stopping its clock and any restart of it
+// still being generated
+func (m *Model) leaveGame() {
+	m.stopGenerating()
+	m.Game.Pause()
+	m.openMenu()
+}
+
+// Go back to the game in memory, or load the saved one
+func (m *Model) continueGame() {
+	if m.inProgress() {
+		m.Game.Resume()
+		m.screen = screenGame
+		return
+	}
+	g, err := storage.LoadGame()
+	if err != nil {
+		m.message = "Could not load the saved game: " + err.Error()
+		return
+	}
+	m.setGame(g)
+}
+
+// Play a game, such as one just generated or loaded. An unfinished game it
+// replaces is saved first, so Continue can still bring it back.
+func (m *Model) setGame(g *game.Game) {
+	if m.inProgress() && m.Game != g {
+		if err := storage.SaveGame(m.Game); err != nil {
+			m.message = "Could not save the game in progress: " + err.Error()
+			return
+		}
+	}
+	m.Game = g
+	m.recorded = g.Finished() // A resumed game was recorded when it ended
+	m.screen = screenGame
+}
+
+// Show the difficulties of a new game
+func (m *Model) openDifficulties() {
+	var items []list.Item
+	for d := sudoku.Easy; d <= sudoku.Expert; d++ {
+		items = append(items, menuItem{
+			title:  d.String(),
+			desc:   difficultyDescriptions[d],
+			action: actionPick,
+			value:  int(d),
+		})
+	}
+	m.picker = m.newList("NEW GAME: DIFFICULTY", items, backKey)
+	m.picker.Select(int(m.pending))
+	m.screen = screenDifficulty
+}
+
+// Show the variants of a new game
+func (m *Model) openVariants() {
+	classic := fmt.Sprintf("Rows, columns and boxes on a %dx%d board", m.settings.Size, m.settings.Size)
+	var items []list.Item
+	for v := sudoku.VariantClassic; ; {
+		desc := classic
+		if v != sudoku.VariantClassic {
+			desc = variantDescriptions[v] + ", on 9x9"
+		}
+		items = append(items, menuItem{title: v.String(), desc: desc, action: actionPick, value: int(v)})
+		if v = v.Next(); v == sudoku.VariantClassic {
+			break
+		}
+	}
+	m.picker = m.newList(fmt.Sprintf("NEW %s GAME: VARIANT", strings.ToUpper(m.pending.String())), items, backKey)
+	m.screen = screenVariant
+}
+
+// Options for a new game of a variant, following the settings
+func (m *Model) newGameOptions(v sudoku.Variant) []sudoku.Option {
+	opts := v.Options()
+	if v == sudoku.VariantClassic && m.settings.Size != 9 {
+		opts = append(opts, sudoku.WithSize(m.settings.Size))
+	}
+	return append(opts, m.symmetryOptions()...)
+}
+
+// Option for the symmetry setting, if it differs from the default
+func (m *Model) symmetryOptions() []sudoku.Option {
+	if m.settings.Symmetry == sudoku.SymmetryRotate180 {
+		return nil
+	}
+	return []sudoku.Option{sudoku.WithSymmetry(m.settings.Symmetry)}
+}
+
+// Show the settings, keeping the selected entry
+func (m *Model) openSettings() {
+	items := []list.Item{
+		menuItem{
+			title:  fmt.Sprintf("Board size: %dx%d", m.settings.Size, m.settings.Size),
+			desc:   "4x4 and 6x6 for young players, 16x16 for marathons",
+			action: actionSize,
+		},
+		menuItem{
+			title:  "Givens pattern: " + m.settings.Symmetry.String(),
+			desc:   "Symmetry of the givens of classic puzzles",
+			action: actionSymmetry,
+		},
+	}
+	if m.screen == screenSettings {
+		m.picker.SetItems(items)
+		return
+	}
+	m.picker = m.newList("SETTINGS", items, backKey)
+	m.screen = screenSettings
+}
+
+// Switch a setting to its next value and save it
+func (m *Model) changeSetting(action menuAction) {
+	switch action {
+	case actionSize:
+		m.settings.Size = game.NextSize(m.settings.Size)
+	case actionSymmetry:
+		m.settings.Symmetry = (m.settings.Symmetry + 1) % (sudoku.SymmetryNone + 1)
+	}
+	if err := storage.SaveSettings(m.settings); err != nil {
+		m.message = "Could not save settings: " + err.Error()
+	}
+	m.openSettings()
+}
+
+// Ask for the path of a puzzle file
+func (m *Model) openLoad() tea.Cmd {
+	m.path.SetValue("")
+	m.screen = screenLoad
+	return m.path.Focus()
+}
+
+// Prompt for the path of a puzzle file
+func newPathInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "File: "
+	ti.Placeholder = "puzzle.sdk"
+	ti.Width = 60
+	return ti
+}
+
+// Read a puzzle file in any supported format, rated for its difficulty
+func loadPuzzle(path string) (*game.Game, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := sudoku.Import(string(data), sudoku.FormatForFile(path, string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return game.NewFromPuzzle(s), nil
+}
+
+// Show the statistics, coming back to the current screen. The clock of a
+// game left for them stops until then.
+func (m *Model) openStats() {
+	m.stats, m.statsErr = storage.LoadStats()
+	if m.screen == screenGame {
+		m.Game.Pause()
+	}
+	m.back = m.screen
+	m.screen = screenStats
+}
+
+// Go back from the statistics to the screen they were opened from
+func (m *Model) closeStats() {
+	m.screen = m.back
+	if m.screen == screenGame {
+		m.Game.Resume()
+	}
+}
+
+// Act on the chosen entry of the menu or of one of its lists
+func (m *Model) choose(item menuItem) tea.Cmd {
+	switch item.action {
+	case actionContinue:
+		m.continueGame()
+	case actionNew:
+		m.openDifficulties()
+	case actionDaily:
+		seed := sudoku.WithSeed(game.DailySeed(time.Now()))
+		return m.startGenerating(game.DailyDifficulty, []sudoku.Option{seed}, true)
+	case actionLoad:
+		return m.openLoad()
+	case actionStats:
+		m.openStats()
+	case actionSettings:
+		m.openSettings()
+	case actionQuit:
+		return m.quit()
+	case actionPick:
+		if m.screen == screenDifficulty {
+			m.pending = sudoku.Difficulty(item.value)
+			m.openVariants()
+			return nil
+		}
+		return m.startGenerating(m.pending, m.newGameOptions(sudoku.Variant(item.value)), true)
+	case actionSize, actionSymmetry:
+		m.changeSetting(item.action)
+	}
+	return nil
+}
+
+// Go back from a list: from the menu to the game in progress, otherwise
+// one step towards the menu
+func (m *Model) goBack() {
+	switch m.screen {
+	case screenMenu:
+		if m.inProgress() {
+			m.continueGame()
+		}
+	case screenVariant:
+		m.openDifficulties()
+	default:
+		m.openMenu()
+	}
+}
+
+// Handle a key on the menu or one of its lists
+func (m *Model) updateList(msg tea.KeyMsg) tea.Cmd {
+	l := &m.picker
+	if m.screen == screenMenu {
+		l = &m.menu
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m.quit()
+	case key.Matches(msg, selectKey):
+		if item, ok := l.SelectedItem().(menuItem); ok {
+			return m.choose(item)
+		}
+		return nil
+	case key.Matches(msg, backKey):
+		m.goBack()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	*l, cmd = l.Update(msg)
+	return cmd
+}
+
+// Handle a key on the file prompt
+func (m *Model) updateLoad(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyEsc:
+		m.openMenu()
+		return nil
+	case tea.KeyEnter:
+		g, err := loadPuzzle(m.path.Value())
+		if err != nil {
+			m.message = "Could not load the puzzle: " + err.Error()
+			return nil
+		}
+		m.setGame(g)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.path, cmd = m.path.Update(msg)
+	return cmd
+}
+
+// Render the file prompt
+func (m *Model) loadView() string {
+	return TitleStyle.Render("📂 LOAD FROM FILE") + "\n\n" + m.path.View() + "\n" +
+		InfoStyle.Render("enter to load, esc to go back")
+}
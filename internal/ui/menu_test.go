@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/internal/storage"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+func TestSetGameSavesGameInProgress(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	old := game.NewWithSeed(sudoku.Easy, 1)
+	m := NewModel(old)
+	m.leaveGame()
+	m.setGame(game.NewWithSeed(sudoku.Hard, 2))
+
+	saved, err := storage.LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Sudoku.Seed != 1 || saved.Sudoku.Grid != old.Sudoku.Grid {
+		t.Error("replaced game was not saved")
+	}
+	if m.Game == old || m.screen != screenGame {
+		t.Error("new game not started")
+	}
+}
+
+func TestRestartOutsideGameKeepsClockPaused(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	m := NewModel(game.NewWithSeed(sudoku.Easy, 1))
+	m.openStats()
+	m.Update(puzzleMsg{generation: m.generation, difficulty: sudoku.Easy, sudoku: sudoku.NewWithSeed(sudoku.Easy, 2)})
+
+	if m.Game.Sudoku.Seed != 2 {
+		t.Fatal("game did not start over")
+	}
+	if !m.Game.Paused {
+		t.Error("clock runs while the statistics are shown")
+	}
+	m.closeStats()
+	if m.Game.Paused {
+		t.Error("clock still paused back in the game")
+	}
+}
+
+func TestLeaveGameCancelsRestart(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := NewModel(game.NewWithSeed(sudoku.Easy, 1))
+	m.startGenerating(sudoku.Easy, []sudoku.Option{sudoku.WithSeed(2)}, false)
+	generation := m.generation
+	m.leaveGame()
+	m.Update(puzzleMsg{generation: generation, difficulty: sudoku.Easy, sudoku: sudoku.NewWithSeed(sudoku.Easy, 2)})
+
+	if m.generating || m.Game.Sudoku.Seed != 1 || !m.Game.Paused {
+		t.Error("restart finished after leaving the game")
+	}
+}
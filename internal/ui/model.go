@@ -1,26 +1,62 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/internal/stats"
+	"github.com/jensderond/sudoku-cli/internal/storage"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
 )
 
 // Model for BubbleTea
 type Model struct {
-	Game *game.Game
-	keys keyMap
-	help help.Model
+	Game       *game.Game
+	keys       keyMap
+	help       help.Model
+	spinner    spinner.Model
+	message    string // Feedback shown below the status line until the next key
+	saveErr    error  // Error from saving on quit, reported after the program exits
+	recorded   bool   // Whether the finished game was added to the statistics
+	stats      *stats.Log
+	statsErr   error
+	screen     screen
+	back       screen // Screen to go back to from the statistics
+	menu       list.Model
+	picker     list.Model // Difficulties, variants or settings
+	path       textinput.Model
+	settings   storage.Settings
+	pending    sudoku.Difficulty // Difficulty picked for a new game
+	width      int
+	height     int
+	generating bool               // A new puzzle is being generated in the background
+	genFor     sudoku.Difficulty  // Difficulty of the puzzle being generated
+	generation int                // Counts generations so stale results can be ignored
+	cancelGen  context.CancelFunc // Stops the running generation
 }
 
 // Timer tick message
 type tickMsg time.Time
 
+// Result of generating a puzzle in the background
+type puzzleMsg struct {
+	generation int
+	difficulty sudoku.Difficulty
+	fresh      bool // Start a new game rather than reuse the current one
+	sudoku     sudoku.Sudoku
+	err        error
+}
+
 // Key bindings
 type keyMap struct {
 	Up         key.Binding
@@ -29,23 +65,35 @@ type keyMap struct {
 	Right      key.Binding
 	Num        key.Binding
 	Delete     key.Binding
+	Notes      key.Binding
+	Undo       key.Binding
+	Redo       key.Binding
+	Hint       key.Binding
+	Copy       key.Binding
+	CopyJSON   key.Binding
 	New        key.Binding
+	Save       key.Binding
+	Stats      key.Binding
 	Quit       key.Binding
+	Menu       key.Binding
 	Help       key.Binding
 	Difficulty key.Binding
+	Variant    key.Binding
+	Size       key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Quit}
+	return []key.Binding{k.Help, k.Menu, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
-		{k.Num, k.Delete},
-		{k.New, k.Difficulty, k.Help, k.Quit},
+		{k.Num, k.Delete, k.Notes},
+		{k.Undo, k.Redo, k.Hint},
+		{k.New, k.Difficulty, k.Variant, k.Size, k.Save, k.Copy, k.CopyJSON, k.Stats, k.Menu, k.Help, k.Quit},
 	}
 }
 
@@ -67,20 +115,56 @@ var keys = keyMap{
 		key.WithHelp("→/l", "move right"),
 	),
 	Num: key.NewBinding(
-		key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
-		key.WithHelp("1-9", "enter number"),
+		key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9", "A", "B", "C", "D", "E", "F", "G"),
+		key.WithHelp("1-9/A-G", "enter number or note, A-G for 10-16"),
 	),
 	Delete: key.NewBinding(
 		key.WithKeys("delete", "backspace", "0", "x"),
 		key.WithHelp("del/x/0", "clear cell"),
 	),
+	Notes: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "toggle notes mode"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo"),
+	),
+	Redo: key.NewBinding(
+		key.WithKeys("ctrl+r", "U"),
+		key.WithHelp("ctrl+r/U", "redo"),
+	),
+	Hint: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "hint, again to fill it in"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy puzzle"),
+	),
+	CopyJSON: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "copy board with notes as JSON"),
+	),
 	New: key.NewBinding(
 		key.WithKeys("n"),
 		key.WithHelp("n", "new game"),
 	),
+	Save: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "save game"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "statistics"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
+		key.WithHelp("q", "save and quit"),
+	),
+	Menu: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "menu"),
 	),
 	Help: key.NewBinding(
 		key.WithKeys("?"),
@@ -90,15 +174,44 @@ var keys = keyMap{
 		key.WithKeys("d"),
 		key.WithHelp("d", "switch difficulty"),
 	),
+	Variant: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "switch variant"),
+	),
+	Size: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "switch board size"),
+	),
 }
 
-// NewModel creates a new UI model
+// NewModel creates a new UI model playing the given game, or showing the
+// menu if it is nil
 func NewModel(g *game.Game) *Model {
-	return &Model{
-		Game: g,
-		keys: keys,
-		help: help.New(),
+	m := &Model{
+		keys:    keys,
+		help:    help.New(),
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(TimerStyle)),
+		path:    newPathInput(),
+		pending: sudoku.Medium,
+		width:   80,
+		height:  24,
+	}
+	// Lists exist from the start so window resizes can reach them
+	m.menu = m.newList("", nil)
+	m.picker = m.newList("", nil)
+
+	var err error
+	m.settings, err = storage.LoadSettings()
+	if err != nil {
+		m.message = "Ignoring settings: " + err.Error()
+	}
+
+	if g == nil {
+		m.openMenu()
+	} else {
+		m.setGame(g)
 	}
+	return m
 }
 
 // Timer command
@@ -117,13 +230,77 @@ func (m *Model) Init() tea.Cmd {
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tickMsg:
-		m.Game.UpdateTime()
+		if m.Game != nil {
+			m.Game.UpdateTime()
+		}
 		return m, tickCmd()
 
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.menu.SetSize(m.width, m.listHeight())
+		m.picker.SetSize(m.width, m.listHeight())
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.generating {
+			return m, nil // Let the spinner stop
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case puzzleMsg:
+		if msg.generation != m.generation {
+			return m, nil // Superseded by a later new game
+		}
+		m.generating = false
+		m.cancelGen = nil
+		if msg.err != nil {
+			m.message = "Could not generate a puzzle: " + msg.err.Error()
+			return m, nil
+		}
+		m.startGame(msg.sudoku, msg.difficulty, msg.fresh)
+		return m, nil
+
 	case tea.KeyMsg:
+		m.message = ""
+
+		switch m.screen {
+		case screenStats:
+			switch {
+			case key.Matches(msg, m.keys.Quit):
+				return m, m.quit()
+			case key.Matches(msg, m.keys.Stats), key.Matches(msg, backKey):
+				m.closeStats()
+			}
+			return m, nil
+		case screenLoad:
+			return m, m.updateLoad(msg)
+		case screenMenu, screenDifficulty, screenVariant, screenSettings:
+			return m, m.updateList(msg)
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
-			return m, tea.Quit
+			return m, m.quit()
+
+		case key.Matches(msg, m.keys.Menu):
+			m.leaveGame()
+
+		case key.Matches(msg, m.keys.Save):
+			if err := storage.SaveGame(m.Game); err != nil {
+				m.message = "Save failed: " + err.Error()
+			} else {
+				m.message = "Game saved"
+			}
+
+		case key.Matches(msg, m.keys.Copy):
+			m.copyPuzzle()
+
+		case key.Matches(msg, m.keys.CopyJSON):
+			if m.copyBoard(&m.Game.Sudoku, sudoku.FormatJSON) {
+				m.message = "Copied board to clipboard as JSON"
+			}
 
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
@@ -131,8 +308,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Difficulty):
 			m.Game.SwitchDifficulty()
 
+		case key.Matches(msg, m.keys.Variant):
+			m.Game.SwitchVariant()
+
+		case key.Matches(msg, m.keys.Size):
+			m.Game.SwitchSize()
+
+		case key.Matches(msg, m.keys.Stats):
+			m.openStats()
+
 		case key.Matches(msg, m.keys.New):
-			m.Game.Reset()
+			opts := append(m.Game.PuzzleOptions(), m.symmetryOptions()...)
+			return m, m.startGenerating(m.Game.Difficulty, opts, false)
 
 		case key.Matches(msg, m.keys.Up):
 			m.Game.HandleMovement(0, -1)
@@ -149,21 +336,182 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Delete):
 			m.Game.HandleClear()
 
-		default:
-			// Handle number input
-			if len(msg.String()) == 1 && msg.String() >= "1" && msg.String() <= "9" {
-				num, _ := strconv.Atoi(msg.String())
-				m.Game.HandleNumberInput(num)
-			}
+		case key.Matches(msg, m.keys.Notes):
+			m.Game.ToggleNotesMode()
+
+		case key.Matches(msg, m.keys.Undo):
+			m.Game.Undo()
+
+		case key.Matches(msg, m.keys.Redo):
+			m.Game.Redo()
+
+		case key.Matches(msg, m.keys.Hint):
+			m.Game.HandleHint()
+
+		case key.Matches(msg, m.keys.Num):
+			// 1-9, then A-G for 10-16 on the larger boards
+			num, _ := strconv.ParseInt(msg.String(), 17, 0)
+			m.Game.HandleNumberInput(int(num))
+		}
+
+		m.recordResult()
+
+	default:
+		if m.screen == screenLoad {
+			var cmd tea.Cmd
+			m.path, cmd = m.path.Update(msg)
+			return m, cmd
 		}
 	}
 
 	return m, nil
 }
 
+// Start a new game with a ready puzzle, or generate one in the background.
+// A fresh game replaces the current one, otherwise the current game starts
+// over keeping the choices made for the next game. A generation still
+// running is cancelled; the current board stays playable until the new
+// puzzle arrives.
+func (m *Model) startGenerating(difficulty sudoku.Difficulty, opts []sudoku.Option, fresh bool) tea.Cmd {
+	m.stopGenerating()
+
+	if len(opts) == 0 {
+		if s, ok := game.TakePuzzle(difficulty); ok {
+			m.startGame(s, difficulty, fresh)
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelGen = cancel
+	m.generation++
+	m.generating = true
+	m.genFor = difficulty
+
+	generation := m.generation
+	generate := func() tea.Msg {
+		s, err := sudoku.Generate(difficulty, append(opts, sudoku.WithContext(ctx))...)
+		return puzzleMsg{generation, difficulty, fresh, s, err}
+	}
+	return tea.Batch(generate, m.spinner.Tick)
+}
+
+// Play a new puzzle, in a fresh game or in the current one
+func (m *Model) startGame(s sudoku.Sudoku, difficulty sudoku.Difficulty, fresh bool) {
+	if fresh || m.Game == nil {
+		m.setGame(game.NewWithPuzzle(s, difficulty))
+		return
+	}
+	m.Game.Start(s, difficulty)
+	m.recorded = false
+	if m.screen != screenGame {
+		m.Game.Pause() // The clock waits until the player is back
+	}
+}
+
+// Cancel the running generation, if any, and ignore its result
+func (m *Model) stopGenerating() {
+	if m.cancelGen != nil {
+		m.cancelGen()
+		m.cancelGen = nil
+		m.generation++
+	}
+	m.generating = false
+}
+
+// Copy the givens of the puzzle as a line, ready to paste into --puzzle.
+// Entries are left out, as wrong ones would make the line unsolvable. A
+// line cannot hold the rules of the variants, so those are copied as JSON.
+func (m *Model) copyPuzzle() {
+	s := &m.Game.Sudoku
+	if v := s.Rules.Variant(); v != sudoku.VariantClassic {
+		if m.copyBoard(s, sudoku.FormatJSON) {
+			m.message = fmt.Sprintf("Copied board to clipboard as JSON, which keeps the %s rules", v)
+		}
+		return
+	}
+
+	givens := sudoku.Sudoku{Grid: s.Givens(), Rules: s.Rules}
+	if m.copyBoard(&givens, sudoku.FormatLine) {
+		m.message = "Copied puzzle to clipboard as a line, ready to paste"
+	}
+}
+
+// Copy a board to the clipboard in the given format, reporting failures
+func (m *Model) copyBoard(s *sudoku.Sudoku, format sudoku.Format) bool {
+	text, err := s.Export(format)
+	if err == nil {
+		err = copyToClipboard(text)
+	}
+	if err != nil {
+		m.message = "Copy failed: " + err.Error()
+		return false
+	}
+	return true
+}
+
+// Save the game and quit
+func (m *Model) quit() tea.Cmd {
+	m.stopGenerating()
+	m.saveErr = m.saveOnQuit()
+	return tea.Quit
+}
+
+// Add the game to the statistics once it has ended
+func (m *Model) recordResult() {
+	if m.recorded || !m.Game.Finished() {
+		return
+	}
+	m.recorded = true
+
+	m.Game.UpdateTime()
+	if err := storage.RecordResult(stats.FromGame(m.Game)); err != nil {
+		m.message = "Could not record statistics: " + err.Error()
+	}
+}
+
+// Save an unfinished game so it can be resumed. A finished game has
+// nothing to resume, so any old save is removed instead.
+func (m *Model) saveOnQuit() error {
+	if m.Game == nil {
+		return nil
+	}
+	if m.Game.Solved || m.Game.GameOver {
+		return storage.RemoveSave()
+	}
+	return storage.SaveGame(m.Game)
+}
+
+// SaveError returns the error from saving on quit, if any
+func (m *Model) SaveError() error {
+	return m.saveErr
+}
+
 // View renders the UI
 func (m *Model) View() string {
-	view := Render(m.Game)
-	view += "\n\n" + m.help.View(m.keys)
+	var view string
+	switch m.screen {
+	case screenStats:
+		return RenderStats(m.stats, m.statsErr)
+	case screenMenu:
+		view = m.menu.View()
+	case screenLoad:
+		view = m.loadView()
+	case screenGame:
+		view = Render(m.Game)
+	default:
+		view = m.picker.View()
+	}
+
+	if m.generating {
+		view += "\n" + m.spinner.View() + InfoStyle.UnsetMarginTop().Render(
+			fmt.Sprintf("Generating %s puzzle...", m.genFor))
+	}
+	if m.message != "" {
+		view += "\n" + InfoStyle.Render(m.message)
+	}
+	if m.screen == screenGame {
+		view += "\n\n" + m.help.View(m.keys)
+	}
 	return view
 }
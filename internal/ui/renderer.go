@@ -2,9 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+
 	"github.com/jensderond/sudoku-cli/internal/game"
+	"github.com/jensderond/sudoku-cli/internal/stats"
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
 )
 
 // Render the complete UI
@@ -24,92 +29,270 @@ func Render(g *game.Game) string {
 	return s.String()
 }
 
-// Render the Sudoku grid
+// Render the Sudoku grid. Once any pencil marks exist every row is drawn
+// taller so notes fit as a block of mini digits, three to a line: three
+// lines on 9x9, six on 16x16. Digits above 9 are shown as the letters A-G.
+// Thick lines mark the edges of the boxes, or of the regions of a jigsaw.
+// Killer cages are outlined with thin lines and dotted inside, and every
+// row gets an extra line for the sums, shown in the first cell of each
+// cage. Cells on the diagonals of a Sudoku X or in the windows of a Hyper
+// Sudoku are shaded.
 func RenderGrid(g *game.Game) string {
 	var s strings.Builder
 	currentValue := g.Sudoku.GetCurrentValue()
+	shape := newBoardShape(&g.Sudoku)
+	size := shape.size
 
-	// Build the grid with borders
-	s.WriteString("┏━━━┯━━━┯━━━┳━━━┯━━━┯━━━┳━━━┯━━━┯━━━┓\n")
-
-	for i := range g.Sudoku.Grid {
-		s.WriteString("┃")
+	lines := 1
+	if g.Sudoku.HasNotes() {
+		lines = (size + 2) / 3
+	}
 
-		for j := range g.Sudoku.Grid[i] {
-			cell := " "
-			if g.Sudoku.Grid[i][j] != 0 {
-				cell = fmt.Sprintf("%d", g.Sudoku.Grid[i][j])
+	for i := range size + 1 {
+		// Horizontal border above row i
+		for j := range size + 1 {
+			s.WriteString(shape.junction(i, j))
+			if j < size {
+				s.WriteString(horizontalLines[shape.horizontalWall(i, j)])
 			}
+		}
+		s.WriteString("\n")
+		if i == size {
+			break
+		}
 
-			// Determine cell appearance based on state
-			var cellDisplay string
-
-			// Check if this cell should be highlighted (same number as cursor)
-			isHighlighted := currentValue != 0 && g.Sudoku.Grid[i][j] == currentValue
+		if g.Sudoku.Rules.Killer() {
+			for j := range size {
+				s.WriteString(verticalLines[shape.verticalWall(i, j)])
+				s.WriteString(renderCageSum(g, i, j, shape.background(i, j)))
+			}
+			s.WriteString(verticalLines[shape.verticalWall(i, size)] + "\n")
+		}
 
-			if i == g.Sudoku.CursorY && j == g.Sudoku.CursorX {
-				// Current position - highlight with brackets
-				cellDisplay = CursorStyle.Render(fmt.Sprintf("[%s]", cell))
-			} else if g.Sudoku.Initial[i][j] {
-				// Initial given numbers
-				if isHighlighted {
-					cellDisplay = HighlightedCellStyle.Render(fmt.Sprintf(" %s ", cell))
-				} else {
-					cellDisplay = InitialCellStyle.Render(fmt.Sprintf(" %s ", cell))
-				}
-			} else if g.Sudoku.Grid[i][j] != 0 {
-				// User-entered numbers
-				if g.Sudoku.Grid[i][j] == g.Sudoku.Solution[i][j] {
-					// Correct
-					if isHighlighted {
-						cellDisplay = HighlightedCellStyle.Render(fmt.Sprintf(" %s ", cell))
-					} else {
-						cellDisplay = CorrectCellStyle.Render(fmt.Sprintf(" %s ", cell))
-					}
+		for line := range lines {
+			for j := range size {
+				s.WriteString(verticalLines[shape.verticalWall(i, j)])
+				if lines == 1 {
+					s.WriteString(renderCell(g, i, j, currentValue, shape.background(i, j)))
 				} else {
-					// Incorrect
-					if isHighlighted {
-						cellDisplay = HighlightedCellStyle.Render(fmt.Sprintf(" %s ", cell))
-					} else {
-						cellDisplay = IncorrectCellStyle.Render(fmt.Sprintf(" %s ", cell))
-					}
+					s.WriteString(renderCellLine(g, i, j, line, lines, currentValue, shape.background(i, j)))
 				}
-			} else {
-				// Empty cell
-				cellDisplay = fmt.Sprintf(" %s ", cell)
 			}
+			s.WriteString(verticalLines[shape.verticalWall(i, size)] + "\n")
+		}
+	}
 
-			s.WriteString(cellDisplay)
+	return s.String()
+}
 
-			// Add vertical separator
-			if j < 8 {
-				if (j+1)%3 == 0 {
-					s.WriteString("┃")
-				} else {
-					s.WriteString("│")
-				}
-			}
+// Sum of a cage in its first cell, blank elsewhere
+func renderCageSum(g *game.Game, i, j int, bg lipgloss.Style) string {
+	k, ok := g.Sudoku.Rules.CageOf(i, j)
+	if !ok || g.Sudoku.Rules.Cages[k].Cells[0] != (sudoku.Cell{Row: i, Col: j}) {
+		return bg.Render("   ")
+	}
+	return bg.Inherit(CageSumStyle).Render(fmt.Sprintf("%-3d", g.Sudoku.Rules.Cages[k].Sum))
+}
+
+// Weights of the lines between cells
+const (
+	noLine = iota
+	thinLine
+	thickLine
+	dottedLine // Between cells of the same cage
+)
+
+var (
+	horizontalLines = [4]string{"   ", "───", "━━━", "┄┄┄"}
+	verticalLines   = [4]string{" ", "│", "┃", "┆"}
+)
+
+// Box drawing characters for the point where lines meet, by the weight of
+// the lines going up, right, down and left. Dotted lines meet like thin ones.
+var junctions = map[[4]int]string{
+	{0, 2, 2, 0}: "┏", {0, 0, 2, 2}: "┓", {2, 2, 0, 0}: "┗", {2, 0, 0, 2}: "┛",
+	{0, 2, 1, 2}: "┯", {0, 2, 2, 2}: "┳", {1, 2, 0, 2}: "┷", {2, 2, 0, 2}: "┻",
+	{2, 1, 2, 0}: "┠", {2, 2, 2, 0}: "┣", {2, 0, 2, 1}: "┨", {2, 0, 2, 2}: "┫",
+	{1, 1, 1, 1}: "┼", {2, 2, 2, 2}: "╋", {2, 1, 2, 1}: "╂", {1, 2, 1, 2}: "┿",
+	{2, 1, 1, 1}: "╀", {1, 1, 2, 1}: "╁", {1, 1, 1, 2}: "┽", {1, 2, 1, 1}: "┾",
+	{2, 1, 1, 2}: "╃", {2, 2, 1, 1}: "╄", {1, 1, 2, 2}: "╅", {1, 2, 2, 1}: "╆",
+	{2, 2, 1, 2}: "╇", {1, 2, 2, 2}: "╈", {2, 1, 2, 2}: "╉", {2, 2, 2, 1}: "╊",
+}
+
+// Regions and cages the lines of the grid are drawn from, and the cells
+// shaded for lying in a diagonal or window
+type boardShape struct {
+	size    int
+	regions [sudoku.MaxSize][sudoku.MaxSize]int
+	cages   [sudoku.MaxSize][sudoku.MaxSize]int // Cage of each cell, -1 outside cages
+	shaded  [sudoku.MaxSize][sudoku.MaxSize]bool
+}
+
+func newBoardShape(s *sudoku.Sudoku) *boardShape {
+	b := &boardShape{size: s.Size()}
+	for i := range b.size {
+		for j := range b.size {
+			b.regions[i][j] = s.Rules.RegionOf(i, j)
+			b.cages[i][j] = -1
+			b.shaded[i][j] = len(s.Rules.ExtraUnits(i, j)) > 0
 		}
-		s.WriteString("┃\n")
-
-		// Add horizontal separator
-		if i < 8 {
-			if (i+1)%3 == 0 {
-				s.WriteString("┣━━━┿━━━┿━━━╋━━━┿━━━┿━━━╋━━━┿━━━┿━━━┫\n")
-			} else {
-				s.WriteString("┠───┼───┼───╂───┼───┼───╂───┼───┼───┨\n")
-			}
+	}
+	for k, c := range s.Rules.Cages {
+		for _, cell := range c.Cells {
+			b.cages[cell.Row][cell.Col] = k
 		}
 	}
+	return b
+}
+
+// Weight of the line between two neighbouring cells
+func (b *boardShape) between(r1, c1, r2, c2 int) int {
+	switch {
+	case b.regions[r1][c1] != b.regions[r2][c2]:
+		return thickLine
+	case b.cages[r1][c1] >= 0 && b.cages[r1][c1] == b.cages[r2][c2]:
+		return dottedLine
+	default:
+		return thinLine
+	}
+}
+
+// Weight of the line left of cell (i, j). Column size is the right edge.
+func (b *boardShape) verticalWall(i, j int) int {
+	if j == 0 || j == b.size {
+		return thickLine
+	}
+	return b.between(i, j-1, i, j)
+}
 
-	s.WriteString("┗━━━┷━━━┷━━━┻━━━┷━━━┷━━━┻━━━┷━━━┷━━━┛\n")
+// Weight of the line above cell (i, j). Row size is the bottom edge.
+func (b *boardShape) horizontalWall(i, j int) int {
+	if i == 0 || i == b.size {
+		return thickLine
+	}
+	return b.between(i-1, j, i, j)
+}
 
+// Character where the lines around the top-left corner of cell (i, j) meet
+func (b *boardShape) junction(i, j int) string {
+	var arms [4]int
+	if i > 0 {
+		arms[0] = b.verticalWall(i-1, j)
+	}
+	if j < b.size {
+		arms[1] = b.horizontalWall(i, j)
+	}
+	if i < b.size {
+		arms[2] = b.verticalWall(i, j)
+	}
+	if j > 0 {
+		arms[3] = b.horizontalWall(i, j-1)
+	}
+	for k, w := range arms {
+		if w == dottedLine {
+			arms[k] = thinLine
+		}
+	}
+	return junctions[arms]
+}
+
+// Background behind a cell: shaded in a diagonal or window, plain elsewhere
+func (b *boardShape) background(i, j int) lipgloss.Style {
+	if b.shaded[i][j] {
+		return ExtraUnitStyle
+	}
+	return lipgloss.NewStyle()
+}
+
+// Render one line of a cell drawn several lines tall: the cell's notes, or
+// its value on the middle line
+func renderCellLine(g *game.Game, i, j, line, lines, currentValue int, bg lipgloss.Style) string {
+	isCursor := i == g.Sudoku.CursorY && j == g.Sudoku.CursorX
+
+	if g.Sudoku.Grid[i][j] != 0 || g.Sudoku.Notes[i][j] == 0 {
+		if line == lines/2 {
+			return renderCell(g, i, j, currentValue, bg)
+		}
+		return bg.Render("   ")
+	}
+
+	// Mini digits line*3+1 to line*3+3
+	var s strings.Builder
+	for num := line*3 + 1; num <= line*3+3; num++ {
+		if !g.Sudoku.HasNote(i, j, num) {
+			s.WriteString(bg.Render(" "))
+			continue
+		}
+
+		digit := digitString(num)
+		switch {
+		case isCursor:
+			s.WriteString(bg.Inherit(CursorStyle).Render(digit))
+		case g.IsHinted(i, j):
+			s.WriteString(bg.Inherit(HintStyle).Render(digit))
+		case num == currentValue:
+			s.WriteString(bg.Inherit(HighlightedCellStyle).Render(digit))
+		default:
+			s.WriteString(bg.Inherit(NoteStyle).Render(digit))
+		}
+	}
 	return s.String()
 }
 
+// Render a single cell's value
+func renderCell(g *game.Game, i, j, currentValue int, bg lipgloss.Style) string {
+	cell := " "
+	if g.Sudoku.Grid[i][j] != 0 {
+		cell = digitString(g.Sudoku.Grid[i][j])
+	}
+
+	// Check if this cell should be highlighted (same number as cursor)
+	isHighlighted := currentValue != 0 && g.Sudoku.Grid[i][j] == currentValue
+
+	if i == g.Sudoku.CursorY && j == g.Sudoku.CursorX {
+		// Current position - highlight with brackets
+		return bg.Inherit(CursorStyle).Render(fmt.Sprintf("[%s]", cell))
+	} else if g.IsHinted(i, j) {
+		// Cell the current hint points at
+		return bg.Inherit(HintStyle).Render(fmt.Sprintf("<%s>", cell))
+	} else if g.Sudoku.Initial[i][j] {
+		// Initial given numbers
+		if isHighlighted {
+			return bg.Inherit(HighlightedCellStyle).Render(fmt.Sprintf(" %s ", cell))
+		}
+		return bg.Inherit(InitialCellStyle).Render(fmt.Sprintf(" %s ", cell))
+	} else if g.Sudoku.Grid[i][j] != 0 {
+		// User-entered numbers
+		if isHighlighted {
+			return bg.Inherit(HighlightedCellStyle).Render(fmt.Sprintf(" %s ", cell))
+		}
+		if g.Sudoku.Grid[i][j] == g.Sudoku.Solution[i][j] {
+			return bg.Inherit(CorrectCellStyle).Render(fmt.Sprintf(" %s ", cell))
+		}
+		return bg.Inherit(IncorrectCellStyle).Render(fmt.Sprintf(" %s ", cell))
+	}
+
+	// Empty cell
+	return bg.Render(fmt.Sprintf(" %s ", cell))
+}
+
+// Digit as shown on the board: 1-9, then A-G for 10-16
+func digitString(v int) string {
+	return strings.ToUpper(strconv.FormatInt(int64(v), 17))
+}
+
 // Render the status line
 func RenderStatus(g *game.Game) string {
 	status := fmt.Sprintf("\nDifficulty: %s", g.Difficulty)
+	if g.Variant != sudoku.VariantClassic {
+		status += fmt.Sprintf(" | Variant: %s", g.Variant)
+	}
+	if g.Size != 9 {
+		status += fmt.Sprintf(" | Size: %dx%d", g.Size, g.Size)
+	}
+	if g.Sudoku.Seed >= 0 {
+		status += fmt.Sprintf(" | Seed: %d", g.Sudoku.Seed)
+	}
 
 	// Lives
 	livesDisplay := " | Lives: " + g.GetLivesDisplay()
@@ -118,11 +301,45 @@ func RenderStatus(g *game.Game) string {
 	// Timer
 	status += TimerStyle.Render(fmt.Sprintf(" | Time: %s", g.GetTimeString()))
 
+	if g.NotesMode {
+		status += NotesModeStyle.Render(" | ✏️  NOTES")
+	}
+
+	if g.HintsUsed > 0 {
+		status += fmt.Sprintf(" | Hints: %d", g.HintsUsed)
+	}
+
 	if g.Solved {
-		status += " | 🎉 SOLVED!"
+		status += fmt.Sprintf(" | 🎉 SOLVED! Score: %d", g.Score())
 	} else if g.GameOver {
 		status += " | 💀 GAME OVER!"
 	}
 
+	if g.Hint != nil {
+		action := "fill it in"
+		if g.Hint.Wrong {
+			action = "clear it"
+		}
+		status += "\n" + HintStyle.Render(fmt.Sprintf("💡 %s (press i again to %s)", g.Hint.Text, action))
+	}
+
 	return InfoStyle.Render(status)
 }
+
+// Render the statistics screen
+func RenderStats(l *stats.Log, err error) string {
+	var s strings.Builder
+
+	s.WriteString(TitleStyle.Render("📊 STATISTICS") + "\n\n")
+
+	if err != nil {
+		s.WriteString(IncorrectCellStyle.Render("Could not load statistics: "+err.Error()) + "\n")
+	} else {
+		var table strings.Builder
+		stats.WriteTable(&table, l)
+		s.WriteString(table.String())
+	}
+
+	s.WriteString(InfoStyle.Render("Press t or esc to go back"))
+	return s.String()
+}
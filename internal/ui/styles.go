@@ -37,4 +37,21 @@ var (
 	HighlightedCellStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("45")). // Lighter cyan for highlighted
 		Bold(true)
+
+	NoteStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244"))
+
+	NotesModeStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
+
+	HintStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("226")). // Yellow
+		Bold(true)
+
+	CageSumStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("141")) // Soft purple
+
+	ExtraUnitStyle = lipgloss.NewStyle().
+		Background(lipgloss.Color("236")) // Dark grey behind diagonals and windows
 )
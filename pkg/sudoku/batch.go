@@ -0,0 +1,78 @@
+package sudoku
+
+import "sync"
+
+// Generate count puzzles from consecutive seeds starting at seed, spread
+// over a number of worker goroutines. Puzzles are passed to emit in seed
+// order, so the output does not depend on the number of workers.
+// Options apply to every puzzle, except that each gets its own seed.
+// Generation stops at the first error, from emit or from the options'
+// context.
+func (g Generator) GenerateBatch(difficulty Difficulty, seed int64, count, workers int, emit func(Sudoku) error, opts ...Option) error {
+	workers = max(workers, 1)
+
+	type result struct {
+		index  int
+		sudoku Sudoku
+		err    error
+	}
+	jobs := make(chan int)
+	results := make(chan result, workers)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(jobs)
+		for i := range count {
+			select {
+			case jobs <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				puzzleOpts := append([]Option{WithSolver(g.Solver)}, opts...)
+				puzzleOpts = append(puzzleOpts, WithSeed(seed+int64(i)))
+				s, err := Generate(difficulty, puzzleOpts...)
+				select {
+				case results <- result{i, s, err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Hold back puzzles that finish early until their turn comes
+	pending := make(map[int]result)
+	next := 0
+	var err error
+	for r := range results {
+		if err != nil {
+			continue // Drain the workers after a failure
+		}
+		pending[r.index] = r
+		for p, ok := pending[next]; ok; p, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if err = p.err; err == nil {
+				err = emit(p.sudoku)
+			}
+			if err != nil {
+				close(done)
+				break
+			}
+		}
+	}
+	return err
+}
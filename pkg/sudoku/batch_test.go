@@ -0,0 +1,52 @@
+package sudoku
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateBatchIsOrderedAndDeterministic(t *testing.T) {
+	var want []int64
+	for i := range 6 {
+		want = append(want, 100+int64(i))
+	}
+
+	for _, workers := range []int{1, 4} {
+		var seeds []int64
+		err := Generator{}.GenerateBatch(Easy, 100, 6, workers, func(s Sudoku) error {
+			if !reflect.DeepEqual(s, NewWithSeed(Easy, s.Seed)) {
+				t.Errorf("puzzle for seed %d differs from NewWithSeed", s.Seed)
+			}
+			seeds = append(seeds, s.Seed)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(seeds) != len(want) {
+			t.Fatalf("%d workers: got %d puzzles, want %d", workers, len(seeds), len(want))
+		}
+		for i := range want {
+			if seeds[i] != want[i] {
+				t.Errorf("%d workers: seeds %v, want %v", workers, seeds, want)
+				break
+			}
+		}
+	}
+}
+
+func TestGenerateBatchStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	emitted := 0
+	err := Generator{}.GenerateBatch(Easy, 0, 50, 4, func(Sudoku) error {
+		emitted++
+		if emitted == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || emitted != 2 {
+		t.Errorf("got %v after %d puzzles, want stop after 2", err, emitted)
+	}
+}
@@ -0,0 +1,175 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+// Cage is a group of cells in a killer puzzle. Its digits add up to Sum
+// and none of them repeats.
+type Cage struct {
+	Sum   int    `json:"sum"`
+	Cells []Cell `json:"cells"`
+}
+
+// Check that the cage fits on the board and its sum can be made
+func (c Cage) validate() error {
+	if len(c.Cells) == 0 || len(c.Cells) > 9 {
+		return fmt.Errorf("cage has %d cells", len(c.Cells))
+	}
+	for _, cell := range c.Cells {
+		if cell.Row < 0 || cell.Row > 8 || cell.Col < 0 || cell.Col > 8 {
+			return fmt.Errorf("cage cell %s is off the board", cell)
+		}
+	}
+	if cageDigits(allDigits, len(c.Cells), c.Sum) == 0 {
+		return fmt.Errorf("no %d different digits add up to %d", len(c.Cells), c.Sum)
+	}
+	return nil
+}
+
+// Index of each cell's cage, -1 for cells outside every cage
+type cageMap [81]int
+
+func newCageMap(cages []Cage) cageMap {
+	var m cageMap
+	for i := range m {
+		m[i] = -1
+	}
+	for k, c := range cages {
+		for _, cell := range c.Cells {
+			m[cell.Row*9+cell.Col] = k
+		}
+	}
+	return m
+}
+
+// Digits that appear in some set of n different digits from avail adding
+// up to sum, indexed by [n][sum][avail]. Filled on first use.
+var (
+	cageComboOnce sync.Once
+	cageCombos    *[10][46][512]uint16
+)
+
+// Digits from avail that can be part of n different digits adding up to
+// sum. Returns 0 when no such digits exist.
+func cageDigits(avail uint16, n, sum int) uint16 {
+	if n < 0 || n > 9 || sum < 0 || sum > 45 {
+		return 0
+	}
+	cageComboOnce.Do(buildCageCombos)
+	return cageCombos[n][sum][avail]
+}
+
+// Fill the combination table. Sets are built up from the smallest digit,
+// so every entry only looks at entries for a smaller set of digits.
+func buildCageCombos() {
+	var combos [10][46][512]uint16
+	var possible [10][46][512]bool
+
+	for avail := range 512 {
+		possible[0][0][avail] = true
+		if avail == 0 {
+			continue
+		}
+		low := avail & -avail
+		d := bits.TrailingZeros(uint(low)) + 1
+		rest := avail &^ low
+
+		for n := 1; n <= 9; n++ {
+			for sum := 1; sum <= 45; sum++ {
+				// Sets without d, then sets with d
+				possible[n][sum][avail] = possible[n][sum][rest]
+				combos[n][sum][avail] = combos[n][sum][rest]
+				if sum >= d && possible[n-1][sum-d][rest] {
+					possible[n][sum][avail] = true
+					combos[n][sum][avail] |= combos[n-1][sum-d][rest] | uint16(low)
+				}
+			}
+		}
+	}
+	cageCombos = &combos
+}
+
+// Largest cage built for each difficulty
+func maxCageSize(difficulty Difficulty) int {
+	switch difficulty {
+	case Easy:
+		return 3
+	case Medium:
+		return 4
+	case Hard:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// Givens revealed up front for each difficulty, before any needed to make
+// the solution unique
+func killerGivens(difficulty Difficulty) int {
+	switch difficulty {
+	case Easy:
+		return 20
+	case Medium:
+		return 8
+	case Hard:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Split the board into connected cages over a solution. Each cage grows
+// from a random free cell into free neighbours whose digits it does not
+// hold yet, up to a random size.
+func buildCages(rng *rand.Rand, solution *Grid, maxSize int) []Cage {
+	order := rng.Perm(81)
+	var taken [81]bool
+	var cages []Cage
+
+	for _, start := range order {
+		if taken[start] {
+			continue
+		}
+		size := 2 + rng.Intn(maxSize-1)
+		cells := []Cell{{start / 9, start % 9}}
+		used := digitBit(solution[start/9][start%9])
+		taken[start] = true
+
+		for len(cells) < size {
+			var next []Cell
+			for _, c := range cells {
+				for _, n := range neighbours(c) {
+					idx := n.Row*9 + n.Col
+					if !taken[idx] && used&digitBit(solution[n.Row][n.Col]) == 0 && !slices.Contains(next, n) {
+						next = append(next, n)
+					}
+				}
+			}
+			if len(next) == 0 {
+				break
+			}
+			n := next[rng.Intn(len(next))]
+			cells = append(cells, n)
+			used |= digitBit(solution[n.Row][n.Col])
+			taken[n.Row*9+n.Col] = true
+		}
+
+		sum := 0
+		for _, c := range cells {
+			sum += solution[c.Row][c.Col]
+		}
+		slices.SortFunc(cells, func(a, b Cell) int { return (a.Row*9 + a.Col) - (b.Row*9 + b.Col) })
+		cages = append(cages, Cage{Sum: sum, Cells: cells})
+	}
+
+	// Cages are numbered in reading order of their first cell
+	slices.SortFunc(cages, func(a, b Cage) int {
+		return (a.Cells[0].Row*9 + a.Cells[0].Col) - (b.Cells[0].Row*9 + b.Cells[0].Col)
+	})
+	return cages
+}
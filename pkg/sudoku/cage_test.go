@@ -0,0 +1,137 @@
+package sudoku
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCageDigits(t *testing.T) {
+	for _, tc := range []struct {
+		avail    uint16
+		n, sum   int
+		expected []int
+	}{
+		{allDigits, 2, 3, []int{1, 2}},
+		{allDigits, 2, 17, []int{8, 9}},
+		{allDigits, 3, 6, []int{1, 2, 3}},
+		{allDigits, 2, 10, []int{1, 2, 3, 4, 6, 7, 8, 9}},
+		{allDigits, 9, 45, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{allDigits &^ digitBit(1), 2, 4, nil},
+		{allDigits, 2, 18, nil},
+		{allDigits, 1, 0, nil},
+	} {
+		if got := maskDigits(cageDigits(tc.avail, tc.n, tc.sum)); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%d digits adding up to %d: got %v, want %v", tc.n, tc.sum, got, tc.expected)
+		}
+	}
+}
+
+func TestGenerateKiller(t *testing.T) {
+	s, err := Generate(Medium, WithSeed(4), WithKiller())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Rules.HasUniqueSolution(s.Grid) {
+		t.Fatal("puzzle is not unique under its cages")
+	}
+
+	// The cages cover the board and add up over the solution
+	var covered int
+	for k, c := range s.Rules.Cages {
+		sum := 0
+		for _, cell := range c.Cells {
+			sum += s.Solution[cell.Row][cell.Col]
+		}
+		if sum != c.Sum {
+			t.Errorf("cage %d adds up to %d, want %d", k+1, sum, c.Sum)
+		}
+		covered += len(c.Cells)
+	}
+	if covered != 81 {
+		t.Errorf("cages cover %d cells, want 81", covered)
+	}
+
+	if again, _ := Generate(Medium, WithSeed(4), WithKiller()); again.Grid != s.Grid || !reflect.DeepEqual(again.Rules.Cages, s.Rules.Cages) {
+		t.Error("the same seed gave a different killer")
+	}
+}
+
+func TestKillerCagesConstrainSolvers(t *testing.T) {
+	s, err := Generate(Easy, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid := s.Solution
+	grid[0][0], grid[0][1] = 0, 0
+	a, b := s.Solution[0][0], s.Solution[0][1]
+
+	rules := Rules{Cages: []Cage{
+		{Sum: a, Cells: []Cell{{0, 0}}},
+		{Sum: b, Cells: []Cell{{0, 1}}},
+	}}
+	if got, ok := rules.Solve(grid); !ok || got != s.Solution {
+		t.Errorf("solved to\n%s", got)
+	}
+
+	// Swapped sums leave no way to fill the two cells
+	rules.Cages[0].Sum, rules.Cages[1].Sum = b, a
+	if _, ok := rules.Solve(grid); ok {
+		t.Error("solved a grid that breaks its cages")
+	}
+}
+
+func TestRulesValidateCages(t *testing.T) {
+	for name, cages := range map[string][]Cage{
+		"overlap":    {{Sum: 3, Cells: []Cell{{0, 0}, {0, 1}}}, {Sum: 4, Cells: []Cell{{0, 1}, {0, 2}}}},
+		"impossible": {{Sum: 2, Cells: []Cell{{0, 0}, {0, 1}}}},
+		"off board":  {{Sum: 5, Cells: []Cell{{0, 9}}}},
+		"empty":      {{Sum: 0}},
+	} {
+		if err := (Rules{Cages: cages}).Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFindCageCombination(t *testing.T) {
+	b := emptyTestBoard()
+	b.cages = [][]int{{0, 1}}
+	b.cageSums = []int{3}
+
+	step, ok := findCageCombination(b)
+	if !ok {
+		t.Fatal("cage combination not found")
+	}
+	if step.Units[0] != (Unit{CageUnit, 0}) {
+		t.Errorf("found in %s, want cage 1", step.Units[0])
+	}
+	for _, e := range step.Eliminations {
+		if e.Digit == 1 || e.Digit == 2 {
+			t.Errorf("unexpected elimination %s<>%d", e.Cell, e.Digit)
+		}
+	}
+	if len(step.Eliminations) != 14 {
+		t.Errorf("got %d eliminations, want 14", len(step.Eliminations))
+	}
+}
+
+func TestKillerExportRoundTrip(t *testing.T) {
+	s, err := Generate(Easy, WithSeed(3), WithKiller())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Export(FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Import(data, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Rules.Cages, s.Rules.Cages) || got.Solution != s.Solution {
+		t.Error("cages or solution lost in the JSON round trip")
+	}
+}
@@ -0,0 +1,234 @@
+package sudoku
+
+// DLXSolver treats the puzzle as an exact cover problem and solves it with
+// Knuth's Algorithm X on dancing links. Every cell needs exactly one digit
+// and every unit needs each digit exactly once; each candidate (cell, digit)
+// covers one cell column plus one column per unit the cell belongs to.
+// Cage sums are not an exact cover constraint, so killers are left to the
+// BacktrackSolver.
+type DLXSolver struct {
+	Rules Rules // Units to search under; the zero value is classic Sudoku
+}
+
+func (s DLXSolver) Solve(grid Grid) (Grid, bool) {
+	if s.Rules.Killer() {
+		return BacktrackSolver(s).Solve(grid)
+	}
+
+	lay := s.Rules.layout()
+	d, ok := newDLX(lay, &grid)
+	if !ok {
+		return grid, false
+	}
+
+	d.limit = 1
+	d.search()
+	if d.count == 0 {
+		return grid, false
+	}
+
+	for _, id := range d.first {
+		cell, digit := id/lay.size, id%lay.size+1
+		grid[cell/lay.size][cell%lay.size] = digit
+	}
+	return grid, true
+}
+
+func (s DLXSolver) CountSolutions(grid Grid, limit int) int {
+	if s.Rules.Killer() {
+		return BacktrackSolver(s).CountSolutions(grid, limit)
+	}
+
+	d, ok := newDLX(s.Rules.layout(), &grid)
+	if !ok {
+		return 0
+	}
+
+	d.limit = limit
+	d.search()
+	return d.count
+}
+
+// Dancing links matrix. Node 0 is the root, nodes 1..columns are the
+// column headers and the rest are candidate nodes.
+type dlx struct {
+	left, right, up, down []int
+	col                   []int // Column header of each node, never modified
+	row                   []int // Candidate id (cell*size + digit-1) of each node, never modified
+	size                  []int // Nodes left in each column, indexed by header
+
+	partial []int // Candidate ids chosen on the current search path
+	first   []int // Candidate ids of the first solution found
+	count   int
+	limit   int
+}
+
+// Copy the layout's empty matrix and pre-select the givens. Returns false
+// if the givens conflict.
+func newDLX(lay *layout, grid *Grid) (*dlx, bool) {
+	lay.dlxOnce.Do(func() {
+		lay.dlxBase, lay.dlxRowNode = buildDLX(lay)
+	})
+
+	base := lay.dlxBase
+	d := &dlx{
+		left:  append([]int(nil), base.left...),
+		right: append([]int(nil), base.right...),
+		up:    append([]int(nil), base.up...),
+		down:  append([]int(nil), base.down...),
+		col:   base.col,
+		row:   base.row,
+		size:  append([]int(nil), base.size...),
+	}
+
+	// Select the rows of the givens
+	covered := make([]bool, len(d.size))
+	for i := range lay.size {
+		for j, v := range grid[i][:lay.size] {
+			if v == 0 {
+				continue
+			}
+			if v > lay.size {
+				return d, false
+			}
+			node := lay.dlxRowNode[(i*lay.size+j)*lay.size+v-1]
+			for k := node; ; {
+				if covered[d.col[k]] {
+					return d, false
+				}
+				covered[d.col[k]] = true
+				d.cover(d.col[k])
+				k = d.right[k]
+				if k == node {
+					break
+				}
+			}
+		}
+	}
+
+	return d, true
+}
+
+// Build the empty matrix for a layout, along with the first node of each
+// candidate's row
+func buildDLX(lay *layout) (*dlx, []int) {
+	n := lay.size * lay.size
+	columns := n + len(lay.units)*lay.size
+	rowLen := 1 + len(lay.cellUnits[0])
+
+	capacity := 1 + columns + n*lay.size*rowLen
+	d := &dlx{
+		left:  make([]int, 0, capacity),
+		right: make([]int, 0, capacity),
+		up:    make([]int, 0, capacity),
+		down:  make([]int, 0, capacity),
+		col:   make([]int, 0, capacity),
+		row:   make([]int, 0, capacity),
+		size:  make([]int, columns+1),
+	}
+
+	// Root and column headers form a circular list
+	for i := 0; i <= columns; i++ {
+		d.left = append(d.left, (i+columns)%(columns+1))
+		d.right = append(d.right, (i+1)%(columns+1))
+		d.up = append(d.up, i)
+		d.down = append(d.down, i)
+		d.col = append(d.col, i)
+		d.row = append(d.row, -1)
+	}
+
+	// Remember one node per candidate so givens can be selected later
+	rowNode := make([]int, n*lay.size)
+
+	for cell := range n {
+		for digit := range lay.size {
+			id := cell*lay.size + digit
+			cols := []int{1 + cell}
+			for _, u := range lay.cellUnits[cell] {
+				cols = append(cols, 1+n+u*lay.size+digit)
+			}
+
+			first := len(d.col)
+			rowNode[id] = first
+			for i, c := range cols {
+				node := len(d.col)
+				d.col = append(d.col, c)
+				d.row = append(d.row, id)
+
+				// Insert at the bottom of the column
+				d.up = append(d.up, d.up[c])
+				d.down = append(d.down, c)
+				d.down[d.up[c]] = node
+				d.up[c] = node
+				d.size[c]++
+
+				// Link into the candidate's circular row
+				d.left = append(d.left, first+(i+len(cols)-1)%len(cols))
+				d.right = append(d.right, first+(i+1)%len(cols))
+			}
+		}
+	}
+
+	return d, rowNode
+}
+
+func (d *dlx) cover(c int) {
+	d.right[d.left[c]] = d.right[c]
+	d.left[d.right[c]] = d.left[c]
+	for i := d.down[c]; i != c; i = d.down[i] {
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.down[d.up[j]] = d.down[j]
+			d.up[d.down[j]] = d.up[j]
+			d.size[d.col[j]]--
+		}
+	}
+}
+
+func (d *dlx) uncover(c int) {
+	for i := d.up[c]; i != c; i = d.up[i] {
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.size[d.col[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+		}
+	}
+	d.right[d.left[c]] = c
+	d.left[d.right[c]] = c
+}
+
+// Algorithm X, always branching on the column with the fewest nodes
+func (d *dlx) search() {
+	if d.right[0] == 0 {
+		if d.count == 0 {
+			d.first = append([]int(nil), d.partial...)
+		}
+		d.count++
+		return
+	}
+
+	c := d.right[0]
+	for j := d.right[c]; j != 0; j = d.right[j] {
+		if d.size[j] < d.size[c] {
+			c = j
+		}
+	}
+	if d.size[c] == 0 {
+		return
+	}
+
+	d.cover(c)
+	for r := d.down[c]; r != c && d.count < d.limit; r = d.down[r] {
+		d.partial = append(d.partial, d.row[r])
+		for j := d.right[r]; j != r; j = d.right[j] {
+			d.cover(d.col[j])
+		}
+
+		d.search()
+
+		for j := d.left[r]; j != r; j = d.left[j] {
+			d.uncover(d.col[j])
+		}
+		d.partial = d.partial[:len(d.partial)-1]
+	}
+	d.uncover(c)
+}
@@ -0,0 +1,31 @@
+// Package sudoku generates, solves and grades Sudoku puzzles, classic 9x9
+// ones and boards from 4x4 up to 16x16.
+//
+// A board is a [Grid]: rows of digits, with 0 for empty cells. A 9x9 board
+// uses the first nine rows and columns of the grid.
+// Grids are read from and written to the common text formats with
+// [ParseGrid], [Import] and [Sudoku.Export].
+//
+// Solving comes in two kinds. [Solve] and [CountSolutions] run a complete
+// search, which finds every solution of any grid; [HasUniqueSolution]
+// checks that a puzzle is well formed. [SolveLogically] solves the way a
+// person would, one named technique at a time, and [Grade] rates a puzzle
+// by the hardest technique it needs.
+//
+// [New] and [NewWithSeed] generate a puzzle whose rating falls in the band
+// of a [Difficulty]. A [Generator] can use another [Solver] for its
+// uniqueness checks and produce puzzles in parallel with
+// [Generator.GenerateBatch].
+//
+// [Rules] describe the units a puzzle is played on. Their zero value is
+// classic Sudoku; [SizedRules] and [WithSize] pick another [BoxShape] and
+// with it the board size. Jigsaw puzzles replace the boxes with irregular
+// [Regions], generated with [WithJigsaw], and killer puzzles add sum
+// [Cage]s, generated with [WithKiller]. Sudoku X and Hyper Sudoku add the
+// diagonals or four extra windows as units, generated with [WithDiagonals]
+// and [WithWindows]. A [Variant] names each kind of puzzle. Rules have
+// their own methods for solving, grading and building puzzles.
+//
+// A [Sudoku] also carries the state of a game in progress: the grid as
+// played, the solution, which cells were given, pencil marks and a cursor.
+package sudoku
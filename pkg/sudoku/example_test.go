@@ -0,0 +1,84 @@
+package sudoku_test
+
+import (
+	"fmt"
+
+	"github.com/jensderond/sudoku-cli/pkg/sudoku"
+)
+
+const puzzle = "003020600900305001001806400008102900700000008006708200002609500800203009005010300"
+
+func ExampleParseGrid() {
+	grid, err := sudoku.ParseGrid(puzzle, sudoku.FormatLine)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(grid.Clues(), "clues")
+	fmt.Println(grid)
+	// Output:
+	// 32 clues
+	// ..3.2.6..9..3.5..1..18.64....81.29..7.......8..67.82....26.95..8..2.3..9..5.1.3..
+}
+
+func ExampleSolve() {
+	grid, _ := sudoku.ParseGrid(puzzle, sudoku.FormatLine)
+	fmt.Println(sudoku.HasUniqueSolution(grid))
+
+	solution, ok := sudoku.Solve(grid)
+	fmt.Println(ok, solution)
+	// Output:
+	// true
+	// true 483921657967345821251876493548132976729564138136798245372689514814253769695417382
+}
+
+func ExampleSolveLogically() {
+	grid, _ := sudoku.ParseGrid(puzzle, sudoku.FormatLine)
+	result := sudoku.SolveLogically(grid)
+	for _, step := range result.Steps[:3] {
+		fmt.Println(step)
+	}
+	fmt.Println(len(result.Steps), "steps, solved:", result.Solved)
+	// Output:
+	// Hidden single in box 1: r2c2=6
+	// Hidden single in box 1: r1c2=8
+	// Hidden single in box 2: r1c6=1
+	// 49 steps, solved: true
+}
+
+func ExampleGrade() {
+	grid, _ := sudoku.ParseGrid(puzzle, sudoku.FormatLine)
+	rating := sudoku.Grade(grid)
+	fmt.Println(rating.Difficulty(), rating.Hardest, rating.Score)
+	// Output:
+	// Easy Hidden single 49
+}
+
+func ExampleNewWithSeed() {
+	s := sudoku.NewWithSeed(sudoku.Hard, 42)
+	fmt.Println(sudoku.Grade(s.Grid).Difficulty())
+	fmt.Println(s.Grid == sudoku.NewWithSeed(sudoku.Hard, 42).Grid)
+	// Output:
+	// Hard
+	// true
+}
+
+func ExampleSudoku_Export() {
+	s, err := sudoku.Import(puzzle, sudoku.FormatLine)
+	if err != nil {
+		panic(err)
+	}
+	out, _ := s.Export(sudoku.FormatSS)
+	fmt.Print(out)
+	// Output:
+	// ..3|.2.|6..
+	// 9..|3.5|..1
+	// ..1|8.6|4..
+	// -----------
+	// ..8|1.2|9..
+	// 7..|...|..8
+	// ..6|7.8|2..
+	// -----------
+	// ..2|6.9|5..
+	// 8..|2.3|..9
+	// ..5|.1.|3..
+}
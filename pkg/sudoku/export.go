@@ -0,0 +1,246 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Board as exchanged in the JSON format. Grids are lines of one character
+// per cell, 81 for 9x9, with '.' for blanks and A-G for the digits 10-16 of
+// larger boards. Notes are keyed by cell, e.g. "r1c2": [3, 7]. Boards other
+// than 9x9 record their size. Jigsaw puzzles add their regions as 81 region
+// numbers, killers their cages, and Sudoku X and Hyper puzzles a flag for
+// their diagonals or windows.
+type document struct {
+	Givens    string           `json:"givens"`
+	Grid      string           `json:"grid"`
+	Solution  string           `json:"solution"`
+	Size      int              `json:"size,omitempty"`
+	Regions   string           `json:"regions,omitempty"`
+	Cages     []cageDocument   `json:"cages,omitempty"`
+	Diagonals bool             `json:"diagonals,omitempty"`
+	Windows   bool             `json:"windows,omitempty"`
+	Notes     map[string][]int `json:"notes,omitempty"`
+	Seed      int64            `json:"seed"`
+}
+
+// Cage as exchanged in the JSON format, with cells named like notes
+type cageDocument struct {
+	Sum   int      `json:"sum"`
+	Cells []string `json:"cells"`
+}
+
+// Write the board in the given format. The text formats hold the current
+// grid, so an exported game carries its progress as givens; JSON keeps the
+// givens, progress, solution and notes apart. Only JSON records the rules
+// of the variants: regions, cages, diagonals and windows.
+func (s *Sudoku) Export(format Format) (string, error) {
+	switch format {
+	case FormatLine:
+		return formatLine(s.Grid, s.Size(), '.') + "\n", nil
+	case FormatSDM:
+		return formatLine(s.Grid, s.Size(), '0') + "\n", nil
+	case FormatSDK:
+		return formatRows(s.Grid, s.Rules.BoxShape(), ""), nil
+	case FormatSS:
+		return formatRows(s.Grid, s.Rules.BoxShape(), "|"), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(s.document(), "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown format %d", format)
+	}
+}
+
+// Write a board of the given size as one line in reading order, 81
+// characters for 9x9
+func formatLine(grid Grid, size int, blank byte) string {
+	var b strings.Builder
+	for i := range size {
+		for _, v := range grid[i][:size] {
+			b.WriteByte(cellChar(v, blank))
+		}
+	}
+	return b.String()
+}
+
+// Write a board as one line per row. A box separator also splits the boxes
+// within each row, and a line of dashes the bands of boxes.
+func formatRows(grid Grid, box BoxShape, boxSep string) string {
+	size := box.Size()
+	bandSep := ""
+	if boxSep != "" {
+		bandSep = strings.Repeat("-", size+size/box.Cols-1) + "\n"
+	}
+
+	var b strings.Builder
+	for i := range size {
+		if i > 0 && i%box.Rows == 0 {
+			b.WriteString(bandSep)
+		}
+		for j, v := range grid[i][:size] {
+			if j > 0 && j%box.Cols == 0 {
+				b.WriteString(boxSep)
+			}
+			b.WriteByte(cellChar(v, '.'))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Character for a digit: 1-9, then A-G for 10-16
+func cellChar(v int, blank byte) byte {
+	switch {
+	case v == 0:
+		return blank
+	case v <= 9:
+		return byte('0' + v)
+	default:
+		return byte('A' + v - 10)
+	}
+}
+
+func (s *Sudoku) document() document {
+	size := s.Size()
+	doc := document{
+		Givens:    formatLine(s.Givens(), size, '.'),
+		Grid:      formatLine(s.Grid, size, '.'),
+		Solution:  formatLine(s.Solution, size, '.'),
+		Diagonals: s.Rules.Diagonals,
+		Windows:   s.Rules.Windows,
+		Seed:      s.Seed,
+	}
+	if size != 9 {
+		doc.Size = size
+	}
+	if s.Rules.Jigsaw() {
+		doc.Regions = s.Rules.Regions.String()
+	}
+	for _, c := range s.Rules.Cages {
+		cage := cageDocument{Sum: c.Sum}
+		for _, cell := range c.Cells {
+			cage.Cells = append(cage.Cells, cell.String())
+		}
+		doc.Cages = append(doc.Cages, cage)
+	}
+	for i := range s.Notes {
+		for j, mask := range s.Notes[i] {
+			if mask == 0 {
+				continue
+			}
+			if doc.Notes == nil {
+				doc.Notes = map[string][]int{}
+			}
+			doc.Notes[Cell{i, j}.String()] = maskDigits(mask)
+		}
+	}
+	return doc
+}
+
+func parseDocument(data string) (document, error) {
+	var doc document
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return doc, fmt.Errorf("reading JSON: %w", err)
+	}
+	return doc, nil
+}
+
+func (doc *document) rules() (Rules, error) {
+	var r Rules
+	if doc.Size != 0 {
+		sized, err := SizedRules(doc.Size)
+		if err != nil {
+			return r, err
+		}
+		r = sized
+	}
+	r.Diagonals, r.Windows = doc.Diagonals, doc.Windows
+	if doc.Regions != "" {
+		regions, err := ParseRegions(doc.Regions)
+		if err != nil {
+			return r, fmt.Errorf("regions: %w", err)
+		}
+		r.Regions = &regions
+	}
+
+	for _, c := range doc.Cages {
+		cage := Cage{Sum: c.Sum}
+		for _, name := range c.Cells {
+			cell, err := parseCellName(name)
+			if err != nil {
+				return r, err
+			}
+			cage.Cells = append(cage.Cells, cell)
+		}
+		r.Cages = append(r.Cages, cage)
+	}
+	return r, r.Validate()
+}
+
+// Read a cell name such as "r1c2"
+func parseCellName(name string) (Cell, error) {
+	var c Cell
+	if _, err := fmt.Sscanf(name, "r%dc%d", &c.Row, &c.Col); err != nil ||
+		c.Row < 1 || c.Row > MaxSize || c.Col < 1 || c.Col > MaxSize {
+		return c, fmt.Errorf("invalid cell %q", name)
+	}
+	return Cell{c.Row - 1, c.Col - 1}, nil
+}
+
+// Rebuild a game from a JSON document. The solution is worked out from the
+// givens again rather than trusted.
+func importDocument(data string) (Sudoku, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return Sudoku{}, err
+	}
+
+	rules, err := doc.rules()
+	if err != nil {
+		return Sudoku{}, err
+	}
+	size := rules.Size()
+	givens, err := parseBoard(doc.Givens, size)
+	if err != nil {
+		return Sudoku{}, fmt.Errorf("givens: %w", err)
+	}
+	s, err := rules.FromGrid(givens)
+	if err != nil {
+		return Sudoku{}, err
+	}
+	s.Seed = doc.Seed
+
+	if doc.Grid != "" {
+		grid, err := parseBoard(doc.Grid, size)
+		if err != nil {
+			return Sudoku{}, fmt.Errorf("grid: %w", err)
+		}
+		for i := range grid {
+			for j, v := range grid[i] {
+				if s.Initial[i][j] && v != givens[i][j] {
+					return Sudoku{}, fmt.Errorf("grid changes the given at %s", Cell{i, j})
+				}
+			}
+		}
+		s.Grid = grid
+	}
+
+	for name, digits := range doc.Notes {
+		c, err := parseCellName(name)
+		if err != nil || c.Row >= size || c.Col >= size {
+			return Sudoku{}, fmt.Errorf("note: invalid cell %q", name)
+		}
+		for _, d := range digits {
+			if d < 1 || d > size {
+				return Sudoku{}, fmt.Errorf("invalid note %d at %s", d, name)
+			}
+			s.Notes[c.Row][c.Col] |= digitBit(d)
+		}
+	}
+	return s, nil
+}
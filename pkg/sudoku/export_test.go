@@ -0,0 +1,112 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	s := NewWithSeed(Medium, 3)
+
+	for _, format := range []Format{FormatLine, FormatSDK, FormatSDM, FormatSS, FormatJSON} {
+		t.Run(format.String(), func(t *testing.T) {
+			data, err := s.Export(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectFormat(data); format != FormatSDM && got != format {
+				t.Errorf("exported %s detected as %s", format, got)
+			}
+
+			imported, err := Import(data, format)
+			if err != nil {
+				t.Fatalf("importing:\n%s\n%v", data, err)
+			}
+			if imported.Grid != s.Grid || imported.Solution != s.Solution || imported.Initial != s.Initial {
+				t.Error("puzzle differs after the round trip")
+			}
+		})
+	}
+}
+
+func TestExportRoundTripSizes(t *testing.T) {
+	for _, size := range []int{4, 6, 12} {
+		s, err := Generate(Easy, WithSize(size), WithSeed(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, format := range []Format{FormatLine, FormatSDK, FormatSS, FormatJSON} {
+			data, err := s.Export(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			imported, err := Import(data, format)
+			if err != nil {
+				t.Fatalf("importing %dx%d %s:\n%s\n%v", size, size, format, data, err)
+			}
+			if imported.Size() != size || imported.Grid != s.Grid || imported.Solution != s.Solution {
+				t.Errorf("%dx%d %s puzzle differs after the round trip", size, size, format)
+			}
+		}
+	}
+}
+
+func TestImportLargeBoard(t *testing.T) {
+	s, err := Generate(Expert, WithSize(16), WithSeed(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Export(FormatLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := Import(data, FormatLine)
+	if err != nil {
+		t.Fatalf("importing 16x16 puzzle: %v", err)
+	}
+	if imported.Grid != s.Grid || imported.Solution != s.Solution {
+		t.Error("16x16 puzzle differs after the round trip")
+	}
+}
+
+func TestExportJSONKeepsProgress(t *testing.T) {
+	s := NewWithSeed(Easy, 4)
+	row, col := firstEmptyCell(t, &s)
+	s.CursorY, s.CursorX = row, col
+	s.SetValue(s.Solution[row][col]%9 + 1)
+	noteRow, noteCol := firstEmptyCell(t, &s)
+	s.Notes[noteRow][noteCol] = digitBit(2) | digitBit(8)
+
+	data, err := s.Export(FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := Import(data, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Grid != s.Grid || imported.Initial != s.Initial || imported.Notes != s.Notes || imported.Seed != 4 {
+		t.Errorf("game differs after the round trip:\n%s", data)
+	}
+	if imported.Initial[row][col] {
+		t.Error("entered digit became a given")
+	}
+}
+
+func TestImportJSONRejectsChangedGivens(t *testing.T) {
+	s := NewWithSeed(Easy, 5)
+	data, err := s.Export(FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grid := formatLine(s.Grid, 9, '.')
+	given := strings.IndexFunc(grid, func(r rune) bool { return r != '.' })
+	changed := grid[:given] + "." + grid[given+1:]
+	data = strings.Replace(data, `"grid": "`+grid, `"grid": "`+changed, 1)
+	if _, err := Import(data, FormatJSON); err == nil {
+		t.Error("accepted a grid that clears a given")
+	}
+}
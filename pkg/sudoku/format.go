@@ -0,0 +1,261 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Text formats puzzles are exchanged in
+type Format int
+
+const (
+	FormatLine Format = iota // One character per cell on one line, '.' or '0' for blanks
+	FormatSDK                // SadMan Sudoku: one line per row
+	FormatSDM                // SadMan Sudoku collection: one line per puzzle
+	FormatSS                 // Simple Sudoku: one line per row with '|' and '-' separators
+	FormatJSON               // JSON document with the givens, progress, solution and notes
+)
+
+var formatNames = []string{"line", "sdk", "sdm", "ss", "json"}
+
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return "unknown"
+	}
+	return formatNames[f]
+}
+
+// Parse a format name such as "sdk", ignoring case
+func ParseFormat(name string) (Format, error) {
+	for f, n := range formatNames {
+		if strings.EqualFold(name, n) {
+			return Format(f), nil
+		}
+	}
+	return FormatLine, fmt.Errorf("unknown format %q", name)
+}
+
+// Pick the format of a puzzle file from its extension, falling back to
+// looking at the contents
+func FormatForFile(path, data string) Format {
+	if f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return f
+	}
+	return DetectFormat(data)
+}
+
+// Guess the format of a puzzle from its text
+func DetectFormat(data string) Format {
+	lines := contentLines(data)
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(data), "{"):
+		return FormatJSON
+	case strings.Contains(data, "|"):
+		return FormatSS
+	case len(lines) == 1 || len(lines) > 0 && len(strings.TrimSpace(lines[0])) >= 81:
+		return FormatLine
+	default:
+		return FormatSDK
+	}
+}
+
+// Returned for puzzles that cannot be played
+var (
+	ErrNoSolution        = errors.New("puzzle has no solution")
+	ErrMultipleSolutions = errors.New("puzzle has more than one solution")
+)
+
+// Read the givens of a puzzle. Collections in the line and .sdm formats
+// may hold several puzzles; the first one is read. The board size follows
+// from the number of cells.
+func ParseGrid(data string, format Format) (Grid, error) {
+	if format == FormatJSON {
+		doc, err := parseDocument(data)
+		if err != nil {
+			return Grid{}, err
+		}
+		r, err := doc.rules()
+		if err != nil {
+			return Grid{}, err
+		}
+		return parseBoard(doc.Givens, r.Size())
+	}
+
+	cells, err := readCells(data, format)
+	if err != nil {
+		return Grid{}, err
+	}
+	return parseCells(cells)
+}
+
+// Collect the cell characters of a puzzle in a text format
+func readCells(data string, format Format) (string, error) {
+	lines := contentLines(data)
+	if len(lines) == 0 {
+		return "", errors.New("no puzzle found")
+	}
+
+	var cells string
+	switch format {
+	case FormatLine, FormatSDM:
+		cells = strings.TrimSpace(lines[0])
+	case FormatSDK:
+		var b strings.Builder
+		for _, line := range lines {
+			b.WriteString(strings.TrimSpace(line))
+		}
+		cells = b.String()
+	case FormatSS:
+		var b strings.Builder
+		for _, line := range lines {
+			if strings.Trim(line, "-+ ") == "" {
+				continue // Separator between bands of boxes
+			}
+			b.WriteString(strings.NewReplacer("|", "", " ", "").Replace(line))
+		}
+		cells = b.String()
+	default:
+		return "", fmt.Errorf("unknown format %d", format)
+	}
+	return strings.TrimSpace(cells), nil
+}
+
+// Read the rules a puzzle is played under. The text formats only give the
+// board size; JSON documents can also hold the rules of the variants.
+func ParseRules(data string, format Format) (Rules, error) {
+	if format != FormatJSON {
+		cells, err := readCells(data, format)
+		if err != nil {
+			return Rules{}, err
+		}
+		size, err := cellsSize(len(cells))
+		if err != nil {
+			return Rules{}, err
+		}
+		return SizedRules(size)
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		return Rules{}, err
+	}
+	return doc.rules()
+}
+
+// Lines of a puzzle text without blank lines and '#' comments
+func contentLines(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Board size with the given number of cells
+func cellsSize(n int) (int, error) {
+	for _, size := range Sizes {
+		if size*size == n {
+			return size, nil
+		}
+	}
+	return 0, fmt.Errorf("expected 81 cells, or 16, 36, 144 or 256 for other sizes, found %d", n)
+}
+
+// Fill a grid from cell characters in reading order, taking the board size
+// from their number
+func parseCells(cells string) (Grid, error) {
+	size, err := cellsSize(len(strings.TrimSpace(cells)))
+	if err != nil {
+		return Grid{}, err
+	}
+	return parseBoard(cells, size)
+}
+
+// Fill a grid of the given size from cell characters in reading order.
+// Digits above 9 are written as the letters A-G, in either case.
+func parseBoard(cells string, size int) (Grid, error) {
+	var grid Grid
+	cells = strings.TrimSpace(cells)
+	if len(cells) != size*size {
+		return grid, fmt.Errorf("expected %d cells, found %d", size*size, len(cells))
+	}
+
+	for idx, ch := range cells {
+		v := -1
+		switch {
+		case ch >= '1' && ch <= '9':
+			v = int(ch - '0')
+		case ch >= 'A' && ch <= 'G':
+			v = int(ch-'A') + 10
+		case ch >= 'a' && ch <= 'g':
+			v = int(ch-'a') + 10
+		case ch == '.' || ch == '0':
+			v = 0
+		}
+		if v < 0 || v > size {
+			return grid, fmt.Errorf("invalid character %q at %s", ch, Cell{idx / size, idx % size})
+		}
+		grid[idx/size][idx%size] = v
+	}
+	return grid, nil
+}
+
+// Build a puzzle from its givens, solving it for the solution. Only puzzles
+// with exactly one solution are accepted. The seed is -1 because the puzzle
+// was not generated.
+func FromGrid(grid Grid) (Sudoku, error) {
+	return Rules{}.FromGrid(grid)
+}
+
+// Build a puzzle played under these rules from its givens
+func (r Rules) FromGrid(grid Grid) (Sudoku, error) {
+	if err := r.Validate(); err != nil {
+		return Sudoku{}, err
+	}
+	solver := r.engine(DefaultSolver)
+	switch solver.CountSolutions(grid, 2) {
+	case 0:
+		return Sudoku{}, ErrNoSolution
+	case 1:
+	default:
+		return Sudoku{}, ErrMultipleSolutions
+	}
+
+	solution, _ := solver.Solve(grid)
+	s := Sudoku{
+		Grid:     grid,
+		Solution: solution,
+		Rules:    r,
+		Seed:     -1,
+	}
+	for i := range grid {
+		for j := range grid[i] {
+			s.Initial[i][j] = grid[i][j] != 0
+		}
+	}
+	return s, nil
+}
+
+// Read a puzzle in the given format and check it can be played. JSON
+// documents also restore the progress and notes of a game.
+func Import(data string, format Format) (Sudoku, error) {
+	if format == FormatJSON {
+		return importDocument(data)
+	}
+
+	grid, err := ParseGrid(data, format)
+	if err != nil {
+		return Sudoku{}, err
+	}
+	rules, err := ParseRules(data, format)
+	if err != nil {
+		return Sudoku{}, err
+	}
+	return rules.FromGrid(grid)
+}
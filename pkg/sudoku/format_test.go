@@ -0,0 +1,112 @@
+package sudoku
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Project Euler 96, grid 1, in each supported format
+const testLine = "003020600900305001001806400008102900700000008006708200002609500800203009005010300"
+
+const testSDK = `#A sample puzzle
+..3.2.6..
+9..3.5..1
+..18.64..
+..81.29..
+7.......8
+..67.82..
+..26.95..
+8..2.3..9
+..5.1.3..
+`
+
+const testSS = `..3|.2.|6..
+9..|3.5|..1
+..1|8.6|4..
+-----------
+..8|1.2|9..
+7..|...|..8
+..6|7.8|2..
+-----------
+..2|6.9|5..
+8..|2.3|..9
+..5|.1.|3..
+`
+
+func TestParseGridFormats(t *testing.T) {
+	want := parseTestGrid(t, testLine)
+
+	tests := []struct {
+		name   string
+		data   string
+		format Format
+	}{
+		{"line", testLine + "\n", FormatLine},
+		{"line with dots", strings.ReplaceAll(testLine, "0", "."), FormatLine},
+		{"sdk", testSDK, FormatSDK},
+		{"sdm", testLine + "\r\n" + strings.Repeat("0", 81) + "\r\n", FormatSDM},
+		{"ss", testSS, FormatSS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid, err := ParseGrid(tt.data, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if grid != want {
+				t.Errorf("grid differs:\n%v\nwant\n%v", grid, want)
+			}
+			if got := DetectFormat(tt.data); tt.format != FormatSDM && got != tt.format {
+				t.Errorf("detected %s, want %s", got, tt.format)
+			}
+		})
+	}
+}
+
+func TestParseGridErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		testLine[:80],
+		testLine[:80] + "x",
+		strings.Repeat(".", 15) + "5", // Too large for 4x4
+	} {
+		if _, err := ParseGrid(data, FormatLine); err == nil {
+			t.Errorf("no error for %q", data)
+		}
+	}
+}
+
+func TestParseGridHexDigits(t *testing.T) {
+	grid, err := ParseGrid("G"+strings.Repeat(".", 254)+"a", FormatLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid[0][0] != 16 || grid[15][15] != 10 {
+		t.Errorf("read %d and %d, want 16 and 10", grid[0][0], grid[15][15])
+	}
+}
+
+func TestImport(t *testing.T) {
+	s, err := Import(testLine, FormatLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Initial[0][2] || s.Initial[0][0] || s.Seed != -1 {
+		t.Error("givens not marked as initial")
+	}
+	if s.IsSolved() {
+		t.Error("imported puzzle starts solved")
+	}
+	s.Grid = s.Solution
+	if !s.IsSolved() {
+		t.Error("solution does not solve the puzzle")
+	}
+
+	if _, err := Import(strings.Repeat("0", 81), FormatLine); !errors.Is(err, ErrMultipleSolutions) {
+		t.Errorf("empty grid: got %v, want ErrMultipleSolutions", err)
+	}
+	if _, err := Import("11"+testLine[2:], FormatLine); !errors.Is(err, ErrNoSolution) {
+		t.Errorf("conflicting givens: got %v, want ErrNoSolution", err)
+	}
+}
@@ -0,0 +1,519 @@
+package sudoku
+
+import (
+	"context"
+	"math/bits"
+	"math/rand"
+)
+
+// Number of puzzles to try before settling for the closest match
+const maxGradeAttempts = 100
+
+// Largest board whose givens are removed while searching for a second
+// solution. Larger boards keep only removals the logical solver can undo,
+// which also proves the solution unique.
+const maxSearchSize = 12
+
+// Generator produces puzzles using a chosen solver engine
+type Generator struct {
+	Solver Solver // Engine for uniqueness checks; DefaultSolver when nil
+}
+
+// Generate a puzzle whose rating falls in the difficulty's band. The same
+// seed and difficulty always give the same puzzle.
+func (g Generator) Generate(difficulty Difficulty, seed int64) (Sudoku, error) {
+	return Generate(difficulty, WithSeed(seed), WithSolver(g.Solver))
+}
+
+// Generate candidates until one meets the options, keeping the closest
+func generateWith(difficulty Difficulty, o *options) (Sudoku, error) {
+	rng := rand.New(rand.NewSource(o.seed))
+
+	if o.killer {
+		s, err := generateKiller(rng, o, difficulty)
+		if err != nil {
+			return Sudoku{}, err
+		}
+		s.Seed = o.seed
+		return s, nil
+	}
+
+	// The rating bands are tuned for 9x9 boards, so other sizes keep the
+	// first candidate
+	if o.rules.Size() != 9 {
+		s, err := generate(rng, o, difficulty)
+		if err != nil {
+			return Sudoku{}, err
+		}
+		s.Seed = o.seed
+		return s, nil
+	}
+
+	var best Sudoku
+	bestClues, bestScore := -1, -1
+
+	for range maxGradeAttempts {
+		s, err := generate(rng, o, difficulty)
+		if err != nil {
+			return Sudoku{}, err
+		}
+
+		clueDistance := 0
+		if o.minClues > 0 {
+			clueDistance = rangeDistance(s.Grid.Clues(), o.minClues, o.maxClues)
+		}
+		scoreDistance := rangeDistance(s.Rules.Grade(s.Grid).Score, o.minScore, o.maxScore)
+		if clueDistance == 0 && scoreDistance == 0 {
+			best = s
+			break
+		}
+		if bestClues < 0 || clueDistance < bestClues ||
+			clueDistance == bestClues && scoreDistance < bestScore {
+			best, bestClues, bestScore = s, clueDistance, scoreDistance
+		}
+	}
+
+	best.Seed = o.seed
+	return best, nil
+}
+
+// Generate a single candidate puzzle for a difficulty
+func generate(rng *rand.Rand, o *options, difficulty Difficulty) (Sudoku, error) {
+	s := Sudoku{Rules: o.rules}
+
+	// Generate a complete valid grid
+	if err := fillSolution(rng, o, &s); err != nil {
+		return s, err
+	}
+
+	// Copy solution to current grid
+	for i := range s.Grid {
+		for j := range s.Grid[i] {
+			s.Grid[i][j] = s.Solution[i][j]
+		}
+	}
+
+	// Remove numbers based on difficulty using optimized strategy, scaling
+	// the counts for 9x9 to the board
+	size := s.Size()
+	cellsToRemove := getCellsToRemove(rng, difficulty) * size * size / 81
+	if o.minClues > 0 {
+		cellsToRemove = size*size - (o.minClues + rng.Intn(o.maxClues-o.minClues+1))
+	}
+	unique := uniqueWith(s.Rules.engine(o.solver))
+	if size > maxSearchSize {
+		unique = func(grid Grid) bool { return s.Rules.SolveLogically(grid).Solved }
+	}
+	err := removeCellsSymmetrically(o.ctx, rng, unique, &s.Grid, size, cellsToRemove, o.symmetry)
+	if err != nil {
+		return s, err
+	}
+
+	markInitial(&s)
+	return s, nil
+}
+
+// Generate a killer puzzle: random cages over a solution, plus givens
+// revealed in random order until the solution is unique. Easier
+// difficulties get smaller cages and some givens up front.
+func generateKiller(rng *rand.Rand, o *options, difficulty Difficulty) (Sudoku, error) {
+	s := Sudoku{Rules: o.rules}
+	if err := fillSolution(rng, o, &s); err != nil {
+		return s, err
+	}
+	s.Rules.Cages = buildCages(rng, &s.Solution, maxCageSize(difficulty))
+
+	solver := s.Rules.engine(o.solver)
+	order := rng.Perm(81)
+	givens := killerGivens(difficulty)
+	revealed := 0
+	for n, idx := range order {
+		if err := o.ctx.Err(); err != nil {
+			return s, err
+		}
+		if n >= givens && isUnique(solver, s.Grid) {
+			break
+		}
+		s.Grid[idx/9][idx%9] = s.Solution[idx/9][idx%9]
+		revealed++
+	}
+
+	// Take back the extra givens the solution stays unique without
+	for n := revealed - 1; n >= givens; n-- {
+		if err := o.ctx.Err(); err != nil {
+			return s, err
+		}
+		idx := order[n]
+		s.Grid[idx/9][idx%9] = 0
+		if !isUnique(solver, s.Grid) {
+			s.Grid[idx/9][idx%9] = s.Solution[idx/9][idx%9]
+		}
+	}
+
+	markInitial(&s)
+	return s, nil
+}
+
+// Mark the filled cells of a new puzzle as givens
+func markInitial(s *Sudoku) {
+	for i := range s.Initial {
+		for j := range s.Initial[i] {
+			s.Initial[i][j] = s.Grid[i][j] != 0
+		}
+	}
+}
+
+// Fill the solution of a new puzzle
+func fillSolution(rng *rand.Rand, o *options, s *Sudoku) error {
+	if o.jigsaw || s.Rules.layout() != classicLayout {
+		return fillLayout(rng, o, s)
+	}
+	generateCompleteGrid(rng, &s.Solution)
+	return nil
+}
+
+// Fill the solution of a puzzle with irregular regions or extra units,
+// drawing new random regions until one can be filled when the options ask
+// for a jigsaw without fixing them
+func fillLayout(rng *rand.Rand, o *options, s *Sudoku) error {
+	for range maxJigsawAttempts {
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		if o.jigsaw {
+			regions := randomRegions(rng)
+			s.Rules.Regions = &regions
+		}
+		s.Solution = Grid{}
+		if generateJigsawGrid(rng, s.Rules.layout(), &s.Solution) {
+			return nil
+		}
+	}
+	return ErrNoSolution
+}
+
+// Region layouts or fills to try before giving up on a jigsaw or other
+// layout
+const maxJigsawAttempts = 50
+
+// Digits already used in each row, column, region, diagonal and window of
+// a layout, as masks with bit d-1 set when d is used
+type usedMasks struct {
+	lay              *layout
+	row, col, region [MaxSize]uint16
+	extra            [maxExtraUnits]uint16
+
+	// Killer cages, if any
+	cageOf *cageMap
+	cages  []cageState
+}
+
+// Digits placed in a cage so far
+type cageState struct {
+	used  uint16
+	left  int // Sum still to place
+	empty int // Cells still empty
+}
+
+// All nine digits of a 9x9 board
+const allDigits uint16 = 0x1FF
+
+func newUsedMasks(lay *layout) usedMasks {
+	return usedMasks{lay: lay}
+}
+
+// Track the sums and digits of killer cages as well
+func (m *usedMasks) addCages(cages []Cage) {
+	if len(cages) == 0 {
+		return
+	}
+	cageOf := newCageMap(cages)
+	m.cageOf = &cageOf
+	m.cages = make([]cageState, len(cages))
+	for k, c := range cages {
+		m.cages[k] = cageState{left: c.Sum, empty: len(c.Cells)}
+	}
+}
+
+// Digits that can still go in a cell
+func (m *usedMasks) candidates(row, col int) uint16 {
+	idx := row*m.lay.size + col
+	cands := m.lay.digits &^ (m.row[row] | m.col[col] | m.region[m.lay.region[idx]])
+	for _, slot := range m.lay.extra[idx] {
+		cands &^= m.extra[slot]
+	}
+	if m.cageOf != nil {
+		if k := m.cageOf[idx]; k >= 0 {
+			c := &m.cages[k]
+			cands &= cageDigits(allDigits&^c.used, c.empty, c.left)
+		}
+	}
+	return cands
+}
+
+func (m *usedMasks) set(row, col, num int) {
+	bit := digitBit(num)
+	idx := row*m.lay.size + col
+	m.row[row] |= bit
+	m.col[col] |= bit
+	m.region[m.lay.region[idx]] |= bit
+	for _, slot := range m.lay.extra[idx] {
+		m.extra[slot] |= bit
+	}
+	if m.cageOf != nil {
+		if k := m.cageOf[idx]; k >= 0 {
+			c := &m.cages[k]
+			c.used |= bit
+			c.left -= num
+			c.empty--
+		}
+	}
+}
+
+func (m *usedMasks) clear(row, col, num int) {
+	bit := digitBit(num)
+	idx := row*m.lay.size + col
+	m.row[row] &^= bit
+	m.col[col] &^= bit
+	m.region[m.lay.region[idx]] &^= bit
+	for _, slot := range m.lay.extra[idx] {
+		m.extra[slot] &^= bit
+	}
+	if m.cageOf != nil {
+		if k := m.cageOf[idx]; k >= 0 {
+			c := &m.cages[k]
+			c.used &^= bit
+			c.left += num
+			c.empty++
+		}
+	}
+}
+
+// Find the empty cell with the fewest candidates. Returns ok=false when
+// the grid is full.
+func mostConstrainedCell(grid *Grid, m *usedMasks) (row, col int, cands uint16, ok bool) {
+	size := m.lay.size
+	best := size + 1
+	for i := range size {
+		for j := range size {
+			if grid[i][j] != 0 {
+				continue
+			}
+			c := m.candidates(i, j)
+			if n := bits.OnesCount16(c); n < best {
+				row, col, cands, ok, best = i, j, c, true, n
+				if n <= 1 {
+					return // Cannot do better than a forced or dead cell
+				}
+			}
+		}
+	}
+	return
+}
+
+// Generate a random complete grid. The same seed always gives the same grid.
+func CompleteGrid(seed int64) Grid {
+	var grid Grid
+	generateCompleteGrid(rand.New(rand.NewSource(seed)), &grid)
+	return grid
+}
+
+// Generate a complete valid Sudoku grid
+func generateCompleteGrid(rng *rand.Rand, grid *Grid) {
+	used := newUsedMasks(classicLayout)
+
+	// Fill diagonal 3x3 boxes first (they don't affect each other)
+	for _, i := range []int{0, 3, 6} {
+		fillBox(rng, grid, i, i, &used)
+	}
+
+	// Fill remaining cells using backtracking
+	solveSudokuFast(rng, grid, &used)
+}
+
+// Fill a 3x3 box with random valid numbers
+func fillBox(rng *rand.Rand, grid *Grid, startRow, startCol int, used *usedMasks) {
+	nums := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	shuffle(rng, nums)
+
+	index := 0
+	for i := range 3 {
+		for j := range 3 {
+			val := nums[index]
+			grid[startRow+i][startCol+j] = val
+			used.set(startRow+i, startCol+j, val)
+			index++
+		}
+	}
+}
+
+// Shuffle slice
+func shuffle(rng *rand.Rand, nums []int) {
+	for i := len(nums) - 1; i > 0; i-- {
+		j := rng.Intn(i + 1)
+		nums[i], nums[j] = nums[j], nums[i]
+	}
+}
+
+// Fill an empty grid on a jigsaw or other layout. Some layouts have no
+// solution or send the search down long dead ends, so it gives up after a
+// number of guesses and reports false.
+func generateJigsawGrid(rng *rand.Rand, lay *layout, grid *Grid) bool {
+	used := newUsedMasks(lay)
+	budget := jigsawGuesses
+	return fillWithin(rng, grid, &used, &budget)
+}
+
+// Guesses allowed when filling a jigsaw grid before trying other regions
+const jigsawGuesses = 20_000
+
+// Fill the grid by backtracking, always branching on the most constrained
+// cell and trying its candidates in random order
+func solveSudokuFast(rng *rand.Rand, grid *Grid, used *usedMasks) bool {
+	budget := -1
+	return fillWithin(rng, grid, used, &budget)
+}
+
+// Fill the grid by backtracking, giving up once budget guesses have been
+// made. A negative budget never runs out.
+func fillWithin(rng *rand.Rand, grid *Grid, used *usedMasks, budget *int) bool {
+	row, col, cands, ok := mostConstrainedCell(grid, used)
+	if !ok {
+		return true
+	}
+
+	nums := maskDigits(cands)
+	shuffle(rng, nums)
+
+	for _, num := range nums {
+		if *budget == 0 {
+			return false
+		}
+		*budget--
+
+		grid[row][col] = num
+		used.set(row, col, num)
+
+		if fillWithin(rng, grid, used, budget) {
+			return true
+		}
+
+		grid[row][col] = 0
+		used.clear(row, col, num)
+	}
+
+	return false
+}
+
+// Check if a puzzle has exactly one solution
+func HasUniqueSolution(grid Grid) bool {
+	return isUnique(DefaultSolver, grid)
+}
+
+// Count number of solutions, branching on the most constrained cell
+func countSolutions(grid *Grid, count *int, limit int, used *usedMasks) {
+	if *count >= limit {
+		return // Early exit once enough solutions are found
+	}
+
+	row, col, cands, ok := mostConstrainedCell(grid, used)
+	if !ok {
+		*count++
+		return
+	}
+
+	for cands != 0 {
+		num := bits.TrailingZeros16(cands) + 1
+		cands &= cands - 1
+
+		grid[row][col] = num
+		used.set(row, col, num)
+
+		countSolutions(grid, count, limit, used)
+
+		grid[row][col] = 0
+		used.clear(row, col, num)
+
+		if *count >= limit {
+			return
+		}
+	}
+}
+
+// Get number of cells to remove based on difficulty
+func getCellsToRemove(rng *rand.Rand, difficulty Difficulty) int {
+	switch difficulty {
+	case Easy:
+		return 40 + rng.Intn(6)
+	case Medium:
+		return 46 + rng.Intn(7)
+	case Hard:
+		return 53 + rng.Intn(6)
+	case Expert:
+		return 59 + rng.Intn(6)
+	default:
+		return 40
+	}
+}
+
+// Uniqueness check using a solver engine
+func uniqueWith(solver Solver) func(Grid) bool {
+	return func(grid Grid) bool { return isUnique(solver, grid) }
+}
+
+// Remove cells in symmetric groups to maintain puzzle quality while reducing checks.
+// Returns the context's error if it is cancelled along the way.
+func removeCellsSymmetrically(ctx context.Context, rng *rand.Rand, unique func(Grid) bool, grid *Grid, size, targetRemoval int, symmetry Symmetry) error {
+	// Create a list of all cell positions
+	type cell struct {
+		row, col int
+	}
+
+	cells := make([]cell, 0, size*size)
+	for i := range size {
+		for j := range size {
+			cells = append(cells, cell{i, j})
+		}
+	}
+
+	// Shuffle cells for randomness
+	rng.Shuffle(len(cells), func(i, j int) {
+		cells[i], cells[j] = cells[j], cells[i]
+	})
+
+	// Try each cell once. A removal that breaks uniqueness would still
+	// break it after further removals, so a second pass cannot help.
+	removed := 0
+	for _, c := range cells {
+		if removed >= targetRemoval {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Clear the cell together with its symmetric partners. They are
+		// either all given or all cleared already.
+		orbit := symmetry.orbit(size, c.row, c.col)
+		if grid[c.row][c.col] == 0 || removed+len(orbit) > targetRemoval {
+			continue
+		}
+
+		var backup [4]int
+		for k, o := range orbit {
+			backup[k] = grid[o.Row][o.Col]
+			grid[o.Row][o.Col] = 0
+		}
+
+		// Every removal must keep the solution unique, otherwise the
+		// player could be penalised for a digit that is also valid
+		if unique(*grid) {
+			removed += len(orbit)
+		} else {
+			for k, o := range orbit {
+				grid[o.Row][o.Col] = backup[k]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,538 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fixed source so test runs are reproducible
+var testRng = rand.New(rand.NewSource(1))
+
+// Helper: check if a grid is a valid Sudoku solution
+func isValidSudokuGrid(grid *Grid) bool {
+	var row, col, box [9][10]bool
+	for i := range 9 {
+		for j := range 9 {
+			num := grid[i][j]
+			if num < 1 || num > 9 {
+				return false
+			}
+			if row[i][num] || col[j][num] || box[(i/3)*3+(j/3)][num] {
+				return false
+			}
+			row[i][num] = true
+			col[j][num] = true
+			box[(i/3)*3+(j/3)][num] = true
+		}
+	}
+	return true
+}
+
+// --- Old generator code for benchmarking ---
+
+// Get next cell position
+func getNextCell(row, col int) (int, int) {
+	col++
+	if col == 9 {
+		col = 0
+		row++
+	}
+	return row, col
+}
+
+// Check if a number is valid at a position
+func isValid(grid *Grid, row, col, num int) bool {
+	// Check row
+	for i := range grid[row] {
+		if grid[row][i] == num {
+			return false
+		}
+	}
+
+	// Check column
+	for i := range grid {
+		if grid[i][col] == num {
+			return false
+		}
+	}
+
+	// Check 3x3 box
+	boxRow, boxCol := (row/3)*3, (col/3)*3
+	for i := range [3]struct{}{} {
+		for j := range [3]struct{}{} {
+			if grid[boxRow+i][boxCol+j] == num {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Helper to copy 2D array
+func copy2DArray(dst, src *Grid) {
+	for i := range src {
+		for j := range src[i] {
+			dst[i][j] = src[i][j]
+		}
+	}
+}
+
+func oldFillBox(grid *Grid, startRow, startCol int) {
+	nums := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	shuffle(testRng, nums)
+	index := 0
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			grid[startRow+i][startCol+j] = nums[index]
+			index++
+		}
+	}
+}
+
+func oldSolveSudoku(grid *Grid, row, col int) bool {
+	if row == 9 {
+		return true
+	}
+	nextRow, nextCol := getNextCell(row, col)
+	if grid[row][col] != 0 {
+		return oldSolveSudoku(grid, nextRow, nextCol)
+	}
+	nums := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	shuffle(testRng, nums)
+	for _, num := range nums {
+		if isValid(grid, row, col, num) {
+			grid[row][col] = num
+			if oldSolveSudoku(grid, nextRow, nextCol) {
+				return true
+			}
+			grid[row][col] = 0
+		}
+	}
+	return false
+}
+
+// Test basic functionality
+func TestNewGenerator(t *testing.T) {
+	var grid Grid
+	used := newUsedMasks(classicLayout)
+	for _, i := range []int{0, 3, 6} {
+		fillBox(testRng, &grid, i, i, &used)
+	}
+	if !solveSudokuFast(testRng, &grid, &used) {
+		t.Fatal("New generator failed to generate a grid")
+	}
+	if !isValidSudokuGrid(&grid) {
+		t.Fatal("New generator produced invalid grid")
+	}
+}
+
+func TestOldGenerator(t *testing.T) {
+	var grid Grid
+	for _, i := range []int{0, 3, 6} {
+		oldFillBox(&grid, i, i)
+	}
+	if !oldSolveSudoku(&grid, 0, 0) {
+		t.Fatal("Old generator failed to generate a grid")
+	}
+	if !isValidSudokuGrid(&grid) {
+		t.Fatal("Old generator produced invalid grid")
+	}
+}
+
+// Benchmark complete grid generation
+func BenchmarkOldGenerator(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		var grid Grid
+		for _, i := range []int{0, 3, 6} {
+			oldFillBox(&grid, i, i)
+		}
+		if !oldSolveSudoku(&grid, 0, 0) {
+			b.Fatal("Old generator failed to generate a grid")
+		}
+	}
+}
+
+func BenchmarkNewGenerator(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		var grid Grid
+		used := newUsedMasks(classicLayout)
+		for _, i := range []int{0, 3, 6} {
+			fillBox(testRng, &grid, i, i, &used)
+		}
+		if !solveSudokuFast(testRng, &grid, &used) {
+			b.Fatal("New generator failed to generate a grid")
+		}
+	}
+}
+
+// Benchmark the generateCompleteGrid function
+func BenchmarkGenerateCompleteGrid(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		var grid Grid
+		generateCompleteGrid(testRng, &grid)
+	}
+}
+
+// Benchmark isValid function (critical for performance)
+func BenchmarkIsValid(b *testing.B) {
+	var grid Grid
+	generateCompleteGrid(testRng, &grid)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = isValid(&grid, 4, 4, 5)
+	}
+}
+
+// Benchmark HasUniqueSolution - THE MAIN BOTTLENECK
+func BenchmarkHasUniqueSolution(b *testing.B) {
+	var grid Grid
+	generateCompleteGrid(testRng, &grid)
+
+	// Remove some cells to create a partial puzzle
+	removed := 0
+	for i := 0; i < 9 && removed < 40; i++ {
+		for j := 0; j < 9 && removed < 40; j++ {
+			if i*9+j < 40 {
+				grid[i][j] = 0
+				removed++
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = HasUniqueSolution(grid)
+	}
+}
+
+// Benchmark solution counting for different numbers of empty cells
+func BenchmarkCountSolutions20Empty(b *testing.B) {
+	benchmarkCountSolutionsWithEmpty(b, 20)
+}
+
+func BenchmarkCountSolutions40Empty(b *testing.B) {
+	benchmarkCountSolutionsWithEmpty(b, 40)
+}
+
+func BenchmarkCountSolutions60Empty(b *testing.B) {
+	benchmarkCountSolutionsWithEmpty(b, 60)
+}
+
+func benchmarkCountSolutionsWithEmpty(b *testing.B, emptyCells int) {
+	var grid Grid
+	generateCompleteGrid(testRng, &grid)
+
+	// Remove cells
+	removed := 0
+	for i := range 9 {
+		for j := range 9 {
+			if removed < emptyCells {
+				grid[i][j] = 0
+				removed++
+			}
+		}
+		if removed >= emptyCells {
+			break
+		}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var testGrid Grid
+		copy2DArray(&testGrid, &grid)
+		solutions := 0
+		// Use the new optimized countSolutions function
+		used, _ := usedTables(&testGrid)
+		countSolutions(&testGrid, &solutions, 2, &used)
+	}
+}
+
+
+// Benchmark puzzle generation by difficulty
+func BenchmarkGeneratePuzzleEasy(b *testing.B) {
+	benchmarkGeneratePuzzle(b, Easy)
+}
+
+func BenchmarkGeneratePuzzleMedium(b *testing.B) {
+	benchmarkGeneratePuzzle(b, Medium)
+}
+
+func BenchmarkGeneratePuzzleHard(b *testing.B) {
+	benchmarkGeneratePuzzle(b, Hard)
+}
+
+func BenchmarkGeneratePuzzleExpert(b *testing.B) {
+	benchmarkGeneratePuzzle(b, Expert)
+}
+
+// Mock the Generate function to benchmark puzzle generation
+func benchmarkGeneratePuzzle(b *testing.B, difficulty Difficulty) {
+	b.StopTimer()
+
+	for n := 0; n < b.N; n++ {
+		var grid Grid
+		generateCompleteGrid(testRng, &grid)
+
+		cellsToRemove := getCellsToRemove(testRng, difficulty)
+
+		b.StartTimer()
+
+		// Use optimized cell removal strategy
+		removeCellsSymmetrically(context.Background(), testRng, uniqueWith(DefaultSolver), &grid, 9, cellsToRemove, SymmetryRotate180)
+
+		b.StopTimer()
+	}
+}
+
+// Test to measure actual generation times
+func TestGenerationTimes(t *testing.T) {
+	difficulties := []struct {
+		name string
+		diff Difficulty
+	}{
+		{"Easy", Easy},
+		{"Medium", Medium},
+		{"Hard", Hard},
+		{"Expert", Expert},
+	}
+
+	for _, d := range difficulties {
+		t.Run(d.name, func(t *testing.T) {
+			times := make([]time.Duration, 5)
+
+			for i := 0; i < 5; i++ {
+				var grid Grid
+				generateCompleteGrid(testRng, &grid)
+
+				start := time.Now()
+
+				cellsToRemove := getCellsToRemove(testRng, d.diff)
+				removeCellsSymmetrically(context.Background(), testRng, uniqueWith(DefaultSolver), &grid, 9, cellsToRemove, SymmetryRotate180)
+
+				times[i] = time.Since(start)
+			}
+
+			// Calculate average
+			var total time.Duration
+			for _, t := range times {
+				total += t
+			}
+			avg := total / 5
+
+			t.Logf("%s difficulty average generation time: %v", d.name, avg)
+			for i, dur := range times {
+				t.Logf("  Run %d: %v", i+1, dur)
+			}
+		})
+	}
+}
+
+// Benchmark the optimized validity check using tracking arrays
+func BenchmarkOptimizedValidityCheck(b *testing.B) {
+	var grid Grid
+	var rowUsed, colUsed, boxUsed [9][10]bool
+
+	// Setup a partial grid
+	generateCompleteGrid(testRng, &grid)
+
+	// Initialize tracking arrays
+	for i := range 9 {
+		for j := range 9 {
+			if grid[i][j] != 0 {
+				rowUsed[i][grid[i][j]] = true
+				colUsed[j][grid[i][j]] = true
+				boxUsed[(i/3)*3+(j/3)][grid[i][j]] = true
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		// Check if we can place 5 at position (4,4)
+		row, col, num := 4, 4, 5
+		boxIdx := (row/3)*3 + (col/3)
+		_ = !rowUsed[row][num] && !colUsed[col][num] && !boxUsed[boxIdx][num]
+	}
+}
+
+// Compare old vs new validity check
+func BenchmarkCompareValidityChecks(b *testing.B) {
+	var grid Grid
+	generateCompleteGrid(testRng, &grid)
+	grid[4][4] = 0 // Clear one cell
+
+	b.Run("Traditional", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			_ = isValid(&grid, 4, 4, 5)
+		}
+	})
+
+	b.Run("Optimized", func(b *testing.B) {
+		var rowUsed, colUsed, boxUsed [9][10]bool
+		// Initialize tracking arrays
+		for i := 0; i < 9; i++ {
+			for j := 0; j < 9; j++ {
+				if grid[i][j] != 0 {
+					rowUsed[i][grid[i][j]] = true
+					colUsed[j][grid[i][j]] = true
+					boxUsed[(i/3)*3+(j/3)][grid[i][j]] = true
+				}
+			}
+		}
+
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			row, col, num := 4, 4, 5
+			boxIdx := (row/3)*3 + (col/3)
+			_ = !rowUsed[row][num] && !colUsed[col][num] && !boxUsed[boxIdx][num]
+		}
+	})
+
+	b.Run("Bitmask", func(b *testing.B) {
+		used, _ := usedTables(&grid)
+
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			row, col, num := 4, 4, 5
+			_ = used.candidates(row, col)&digitBit(num) != 0
+		}
+	})
+}
+
+// Helper: count solutions of a puzzle with the backtracking counter
+func countPuzzleSolutions(grid Grid) int {
+	used, ok := usedTables(&grid)
+	if !ok {
+		return 0
+	}
+
+	solutions := 0
+	countSolutions(&grid, &solutions, 2, &used)
+	return solutions
+}
+
+// Every generated puzzle must have exactly one solution
+func TestGeneratedPuzzlesAreUnique(t *testing.T) {
+	runs := 50
+	if testing.Short() {
+		runs = 5
+	}
+
+	for _, d := range []Difficulty{Easy, Medium, Hard, Expert} {
+		t.Run(d.String(), func(t *testing.T) {
+			for seed := range int64(runs) {
+				s := NewWithSeed(d, seed)
+				if n := countPuzzleSolutions(s.Grid); n != 1 {
+					t.Fatalf("seed %d: puzzle has %d solutions", seed, n)
+				}
+				if !isValidSudokuGrid(&s.Solution) {
+					t.Fatalf("seed %d: invalid solution", seed)
+				}
+				for r := range 9 {
+					for c := range 9 {
+						if s.Initial[r][c] && s.Grid[r][c] != s.Solution[r][c] {
+							t.Fatalf("seed %d: given at r%dc%d does not match solution", seed, r+1, c+1)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// Generated puzzles should be graded into the requested band
+func TestNewMatchesDifficulty(t *testing.T) {
+	for _, d := range []Difficulty{Easy, Medium, Hard, Expert} {
+		s := NewWithSeed(d, 7)
+		if rating := Grade(s.Grid); rating.Difficulty() != d {
+			t.Errorf("%s puzzle rated %s (score %d, hardest %s)", d, rating.Difficulty(), rating.Score, rating.Hardest)
+		}
+	}
+}
+
+// The same seed must always give the same puzzle
+func TestNewWithSeedIsDeterministic(t *testing.T) {
+	for _, d := range []Difficulty{Easy, Medium} {
+		a := NewWithSeed(d, 12345)
+		b := NewWithSeed(d, 12345)
+		if a.Grid != b.Grid || a.Solution != b.Solution {
+			t.Errorf("%s: seed 12345 gave two different puzzles", d)
+		}
+		if a.Seed != 12345 {
+			t.Errorf("%s: puzzle records seed %d", d, a.Seed)
+		}
+
+		if c := NewWithSeed(d, 54321); c.Solution == a.Solution {
+			t.Errorf("%s: different seeds gave the same puzzle", d)
+		}
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	for _, d := range []Difficulty{Easy, Medium, Hard, Expert} {
+		got, err := ParseDifficulty(strings.ToLower(d.String()))
+		if err != nil || got != d {
+			t.Errorf("ParseDifficulty(%q) = %v, %v", strings.ToLower(d.String()), got, err)
+		}
+	}
+	if _, err := ParseDifficulty("impossible"); err == nil {
+		t.Error("expected error for unknown difficulty")
+	}
+}
+
+// Helper: a solved grid with the first emptyCells cells (row-major) cleared
+func gridWithEmpty(emptyCells int) Grid {
+	var grid Grid
+	generateCompleteGrid(testRng, &grid)
+	for i := range emptyCells {
+		grid[i/9][i%9] = 0
+	}
+	return grid
+}
+
+// Compare the solver engines on the same puzzles as the countSolutions benchmarks
+func BenchmarkCompareSolvers(b *testing.B) {
+	solvers := []struct {
+		name   string
+		solver Solver
+	}{
+		{"Backtrack", BacktrackSolver{}},
+		{"DLX", DLXSolver{}},
+	}
+
+	for _, empty := range []int{20, 40, 60} {
+		grid := gridWithEmpty(empty)
+		for _, s := range solvers {
+			b.Run(fmt.Sprintf("%s/%dEmpty", s.name, empty), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					_ = s.solver.CountSolutions(grid, 2)
+				}
+			})
+		}
+	}
+}
+
+// Compare the solver engines when generating whole puzzles
+func BenchmarkGenerateWithSolver(b *testing.B) {
+	for _, s := range []struct {
+		name   string
+		solver Solver
+	}{
+		{"Backtrack", BacktrackSolver{}},
+		{"DLX", DLXSolver{}},
+	} {
+		b.Run(s.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				var grid Grid
+				generateCompleteGrid(testRng, &grid)
+				removeCellsSymmetrically(context.Background(), testRng, uniqueWith(s.solver), &grid, 9, getCellsToRemove(testRng, Hard), SymmetryRotate180)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package sudoku
+
+// Points in each difficulty band: Easy scores 0-99, Medium 100-199 and so on
+const bandSize = 100
+
+// Score given to puzzles the logical solver cannot finish
+const UnsolvableScore = 4 * bandSize
+
+// Rating of a puzzle by the techniques needed to solve it
+type Rating struct {
+	Score   int       // Band of the hardest technique plus how often that band was needed
+	Hardest Technique // Hardest technique needed, or used before logic got stuck
+	Uses    int       // Steps that used a technique from the hardest band
+	Solved  bool      // False when logic alone cannot finish the puzzle
+}
+
+// Difficulty band the rating falls in. Unsolvable puzzles are above Expert.
+func (r Rating) Difficulty() Difficulty {
+	return Difficulty(r.Score / bandSize)
+}
+
+// Range of scores that make up a difficulty band
+func (d Difficulty) ScoreRange() (lo, hi int) {
+	return int(d) * bandSize, int(d)*bandSize + bandSize - 1
+}
+
+// Difficulty band a technique belongs to
+func (t Technique) Difficulty() Difficulty {
+	switch {
+	case t <= HiddenSingle:
+		return Easy
+	case t <= CageCombination:
+		return Medium
+	case t <= HiddenTriple:
+		return Hard
+	default:
+		return Expert
+	}
+}
+
+// Points a single use of a technique adds within its band
+func (t Technique) weight() int {
+	switch t {
+	case HiddenSingle:
+		return 1
+	case NakedSingle:
+		return 4
+	case Pointing, BoxLineReduction, CageCombination:
+		return 8
+	case NakedPair:
+		return 15
+	case HiddenPair:
+		return 18
+	case NakedTriple:
+		return 22
+	case HiddenTriple:
+		return 25
+	case XWing:
+		return 30
+	case XYWing:
+		return 35
+	case Swordfish:
+		return 40
+	default:
+		return 45
+	}
+}
+
+// Grade a puzzle by solving it logically
+func Grade(grid Grid) Rating {
+	return Rules{}.Grade(grid)
+}
+
+// Grade a puzzle played under these rules
+func (r Rules) Grade(grid Grid) Rating {
+	return rateSteps(r.SolveLogically(grid))
+}
+
+func rateSteps(result LogicResult) Rating {
+	var rating Rating
+	for _, step := range result.Steps {
+		if step.Technique > rating.Hardest {
+			rating.Hardest = step.Technique
+		}
+	}
+
+	band := rating.Hardest.Difficulty()
+	points := 0
+	for _, step := range result.Steps {
+		if step.Technique.Difficulty() == band {
+			rating.Uses++
+			points += step.Technique.weight()
+		}
+	}
+	if !result.Solved {
+		rating.Score = UnsolvableScore
+		return rating
+	}
+	rating.Solved = true
+
+	lo, hi := band.ScoreRange()
+	rating.Score = min(lo+points, hi)
+	return rating
+}
+
+// How far a value lies outside the range lo to hi
+func rangeDistance(v, lo, hi int) int {
+	switch {
+	case v < lo:
+		return lo - v
+	case v > hi:
+		return v - hi
+	default:
+		return 0
+	}
+}
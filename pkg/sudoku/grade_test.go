@@ -0,0 +1,70 @@
+package sudoku
+
+import "testing"
+
+func TestGradeSinglesPuzzle(t *testing.T) {
+	grid := parseTestGrid(t, "003020600900305001001806400008102900700000008006708200002609500800203009005010300")
+
+	rating := Grade(grid)
+	if !rating.Solved {
+		t.Fatal("puzzle should be solvable by logic")
+	}
+	if d := rating.Difficulty(); d != Easy && d != Medium {
+		t.Errorf("singles-only puzzle rated %s (score %d)", d, rating.Score)
+	}
+}
+
+func TestGradeUnsolvable(t *testing.T) {
+	var grid Grid
+
+	rating := Grade(grid)
+	if rating.Solved || rating.Score != UnsolvableScore {
+		t.Errorf("empty grid rated %+v", rating)
+	}
+	if rating.Difficulty() <= Expert {
+		t.Errorf("unsolvable puzzle should rate above Expert, got %s", rating.Difficulty())
+	}
+
+	stuck := rateSteps(LogicResult{Steps: []Step{{Technique: HiddenSingle}, {Technique: XWing}}})
+	if stuck.Solved || stuck.Score != UnsolvableScore || stuck.Hardest != XWing || stuck.Uses != 1 {
+		t.Errorf("puzzle stuck after an X-Wing rated %+v", stuck)
+	}
+}
+
+func TestGradeBands(t *testing.T) {
+	tests := []struct {
+		steps []Technique
+		want  Difficulty
+	}{
+		{[]Technique{HiddenSingle, HiddenSingle}, Easy},
+		{[]Technique{HiddenSingle, NakedSingle}, Medium},
+		{[]Technique{Pointing, HiddenSingle}, Medium},
+		{[]Technique{NakedPair, HiddenSingle}, Hard},
+		{[]Technique{HiddenTriple, XWing, HiddenSingle}, Expert},
+	}
+
+	for _, tt := range tests {
+		var result LogicResult
+		result.Solved = true
+		for _, tech := range tt.steps {
+			result.Steps = append(result.Steps, Step{Technique: tech})
+		}
+
+		rating := rateSteps(result)
+		if rating.Difficulty() != tt.want {
+			t.Errorf("%v rated %s (score %d), want %s", tt.steps, rating.Difficulty(), rating.Score, tt.want)
+		}
+	}
+}
+
+func TestGradeScoreGrowsWithUses(t *testing.T) {
+	once := rateSteps(LogicResult{Solved: true, Steps: []Step{{Technique: NakedPair}}})
+	twice := rateSteps(LogicResult{Solved: true, Steps: []Step{{Technique: NakedPair}, {Technique: NakedPair}}})
+
+	if twice.Score <= once.Score {
+		t.Errorf("two naked pairs scored %d, one scored %d", twice.Score, once.Score)
+	}
+	if twice.Uses != 2 || twice.Hardest != NakedPair {
+		t.Errorf("unexpected rating %+v", twice)
+	}
+}
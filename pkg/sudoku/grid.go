@@ -0,0 +1,63 @@
+package sudoku
+
+// Largest board size: 16x16 with 4x4 boxes
+const MaxSize = 16
+
+// Grid holds a board by row and column, with 0 for empty cells. Boards
+// smaller than MaxSize use the top left corner and leave the rest empty.
+type Grid [MaxSize][MaxSize]int
+
+// Write the grid as one line in reading order with '.' for blanks, 81
+// characters for a 9x9 board. The board is taken to be the smallest size
+// that holds every filled cell.
+func (g Grid) String() string {
+	return formatLine(g, g.size(), '.')
+}
+
+// Count the filled cells
+func (g Grid) Clues() int {
+	clues := 0
+	for i := range g {
+		for _, v := range g[i] {
+			if v != 0 {
+				clues++
+			}
+		}
+	}
+	return clues
+}
+
+// Check if every cell is filled, taking the board size as String does
+func (g Grid) Full() bool {
+	size := g.size()
+	for i := range size {
+		for j := range size {
+			if g[i][j] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Smallest supported board size holding every filled cell, 9 for an
+// empty grid
+func (g Grid) size() int {
+	used := 0
+	for i := range g {
+		for j, v := range g[i] {
+			if v != 0 {
+				used = max(used, i+1, j+1)
+			}
+		}
+	}
+	if used == 0 {
+		return 9
+	}
+	for _, size := range Sizes {
+		if size >= used {
+			return size
+		}
+	}
+	return MaxSize
+}
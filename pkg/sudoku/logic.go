@@ -0,0 +1,854 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Technique is a human solving technique. They are ordered from easiest to
+// hardest, which is also the order the logical solver tries them in.
+type Technique int
+
+const (
+	HiddenSingle Technique = iota
+	NakedSingle
+	Pointing
+	BoxLineReduction
+	CageCombination
+	NakedPair
+	HiddenPair
+	NakedTriple
+	HiddenTriple
+	XWing
+	Swordfish
+	XYWing
+	XYZWing
+)
+
+func (t Technique) String() string {
+	switch t {
+	case HiddenSingle:
+		return "Hidden single"
+	case NakedSingle:
+		return "Naked single"
+	case Pointing:
+		return "Pointing"
+	case BoxLineReduction:
+		return "Box/line reduction"
+	case CageCombination:
+		return "Cage combination"
+	case NakedPair:
+		return "Naked pair"
+	case HiddenPair:
+		return "Hidden pair"
+	case NakedTriple:
+		return "Naked triple"
+	case HiddenTriple:
+		return "Hidden triple"
+	case XWing:
+		return "X-Wing"
+	case Swordfish:
+		return "Swordfish"
+	case XYWing:
+		return "XY-Wing"
+	case XYZWing:
+		return "XYZ-Wing"
+	default:
+		return "Unknown"
+	}
+}
+
+// Candidate is a digit at a cell, as placed or eliminated by a step
+type Candidate struct {
+	Cell
+	Digit int
+}
+
+// Step is a single deduction made by the logical solver
+type Step struct {
+	Technique    Technique
+	Units        []Unit      // Units the pattern was found in
+	Digits       []int       // Digits forming the pattern
+	Cells        []Cell      // Cells forming the pattern
+	Placements   []Candidate // Digits placed by this step
+	Eliminations []Candidate // Candidates removed by this step
+}
+
+// Describe the step, e.g. "Hidden single in box 5: r4c5=7"
+func (s Step) String() string {
+	var b strings.Builder
+	b.WriteString(s.Technique.String())
+
+	if len(s.Digits) > 0 {
+		digits := make([]string, len(s.Digits))
+		for i, d := range s.Digits {
+			digits[i] = fmt.Sprint(d)
+		}
+		b.WriteString(" {" + strings.Join(digits, ",") + "}")
+	}
+	if len(s.Units) > 0 {
+		units := make([]string, len(s.Units))
+		for i, u := range s.Units {
+			units[i] = u.String()
+		}
+		b.WriteString(" in " + strings.Join(units, ", "))
+	}
+	if len(s.Cells) > 0 {
+		cells := make([]string, len(s.Cells))
+		for i, c := range s.Cells {
+			cells[i] = c.String()
+		}
+		b.WriteString(" at " + strings.Join(cells, ", "))
+	}
+
+	var effects []string
+	for _, p := range s.Placements {
+		effects = append(effects, fmt.Sprintf("%s=%d", p.Cell, p.Digit))
+	}
+	for _, e := range s.Eliminations {
+		effects = append(effects, fmt.Sprintf("%s<>%d", e.Cell, e.Digit))
+	}
+	b.WriteString(": " + strings.Join(effects, ", "))
+
+	return b.String()
+}
+
+// Outcome of solving a puzzle with human techniques
+type LogicResult struct {
+	Steps  []Step // Deductions in the order they were made
+	Grid   Grid   // Grid after applying every step
+	Solved bool   // Whether the steps filled the whole grid
+}
+
+// Solve a puzzle the way a person would, recording every step. The solver
+// stops when the grid is full or when none of its techniques apply.
+func SolveLogically(grid Grid) LogicResult {
+	return Rules{}.SolveLogically(grid)
+}
+
+// Solve a puzzle logically under these rules
+func (r Rules) SolveLogically(grid Grid) LogicResult {
+	result := LogicResult{Grid: grid}
+
+	b, ok := r.newBoard(&grid)
+	if !ok {
+		return result
+	}
+
+	for !b.solved() && !b.broken() {
+		step, found := b.nextStep()
+		if !found {
+			break
+		}
+		b.apply(step)
+		result.Steps = append(result.Steps, step)
+	}
+
+	result.Grid = b.grid()
+	result.Solved = b.solved()
+	return result
+}
+
+// Find the next cell that logic can fill from the current grid. Returns the
+// steps needed to get there, ending with the step that places the digit.
+func NextPlacement(grid Grid) ([]Step, bool) {
+	return Rules{}.NextPlacement(grid)
+}
+
+// Find the next cell that logic can fill under these rules
+func (r Rules) NextPlacement(grid Grid) ([]Step, bool) {
+	b, ok := r.newBoard(&grid)
+	if !ok {
+		return nil, false
+	}
+
+	var steps []Step
+	for !b.solved() && !b.broken() {
+		step, found := b.nextStep()
+		if !found {
+			break
+		}
+		steps = append(steps, step)
+		if len(step.Placements) > 0 {
+			return steps, true
+		}
+		b.apply(step)
+	}
+	return nil, false
+}
+
+// Candidate state used by the logical solver
+type board struct {
+	lay    *layout
+	values []int
+	cands  []uint16 // Bit d-1 is set while d is still possible; 0 for filled cells
+
+	cages    [][]int // Cells of each killer cage
+	cageSums []int
+}
+
+func digitBit(d int) uint16 {
+	return 1 << (d - 1)
+}
+
+// List the digits set in a candidate mask
+func maskDigits(mask uint16) []int {
+	var digits []int
+	for mask != 0 {
+		digits = append(digits, bits.TrailingZeros16(mask)+1)
+		mask &= mask - 1
+	}
+	return digits
+}
+
+// Build a board from a grid. Returns false if the givens contradict each other.
+func newBoard(lay *layout, grid *Grid) (*board, bool) {
+	n := lay.size * lay.size
+	b := &board{
+		lay:    lay,
+		values: make([]int, n),
+		cands:  make([]uint16, n),
+	}
+
+	for i := range b.cands {
+		b.cands[i] = lay.digits
+	}
+
+	for i := range lay.size {
+		for j, v := range grid[i][:lay.size] {
+			if v == 0 {
+				continue
+			}
+			idx := i*lay.size + j
+			if b.cands[idx]&digitBit(v) == 0 {
+				return b, false // A peer already holds this digit
+			}
+			b.place(idx, v)
+		}
+	}
+	return b, true
+}
+
+// Build a board for a grid under a set of rules
+func (r Rules) newBoard(grid *Grid) (*board, bool) {
+	b, ok := newBoard(r.layout(), grid)
+	for _, c := range r.Cages {
+		cells := make([]int, len(c.Cells))
+		for i, cell := range c.Cells {
+			cells[i] = cell.Row*b.lay.size + cell.Col
+		}
+		b.cages = append(b.cages, cells)
+		b.cageSums = append(b.cageSums, c.Sum)
+	}
+	return b, ok
+}
+
+func (b *board) place(idx, d int) {
+	b.values[idx] = d
+	b.cands[idx] = 0
+	bit := digitBit(d)
+	for _, p := range b.lay.peers[idx] {
+		b.cands[p] &^= bit
+	}
+}
+
+func (b *board) apply(s Step) {
+	for _, p := range s.Placements {
+		b.place(p.Row*b.lay.size+p.Col, p.Digit)
+	}
+	for _, e := range s.Eliminations {
+		b.cands[e.Row*b.lay.size+e.Col] &^= digitBit(e.Digit)
+	}
+}
+
+func (b *board) grid() Grid {
+	var grid Grid
+	for idx, v := range b.values {
+		grid[idx/b.lay.size][idx%b.lay.size] = v
+	}
+	return grid
+}
+
+func (b *board) solved() bool {
+	for _, v := range b.values {
+		if v == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Check for a contradiction: an empty cell without candidates, or a unit
+// with no room left for one of its digits
+func (b *board) broken() bool {
+	all := b.lay.digits
+	for _, cells := range b.lay.unitCells {
+		var seen uint16
+		for _, c := range cells {
+			if b.values[c] != 0 {
+				seen |= digitBit(b.values[c])
+			} else if b.cands[c] == 0 {
+				return true
+			}
+			seen |= b.cands[c]
+		}
+		if seen != all {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *board) candidate(idx, d int) Candidate {
+	return Candidate{b.lay.cell(idx), d}
+}
+
+func (b *board) cellList(idxs []int) []Cell {
+	cells := make([]Cell, len(idxs))
+	for i, idx := range idxs {
+		cells[i] = b.lay.cell(idx)
+	}
+	return cells
+}
+
+func (b *board) unitList(idxs ...int) []Unit {
+	units := make([]Unit, len(idxs))
+	for i, u := range idxs {
+		units[i] = b.lay.units[u]
+	}
+	return units
+}
+
+// Check whether a cell belongs to a unit
+func (b *board) inUnit(idx, unit int) bool {
+	for _, u := range b.lay.cellUnits[idx] {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// Techniques in the order they are tried
+var techniques = []func(b *board) (Step, bool){
+	findHiddenSingle,
+	findNakedSingle,
+	func(b *board) (Step, bool) { return findLockedCandidates(b, true) },
+	func(b *board) (Step, bool) { return findLockedCandidates(b, false) },
+	findCageCombination,
+	func(b *board) (Step, bool) { return findNakedSubset(b, 2) },
+	func(b *board) (Step, bool) { return findHiddenSubset(b, 2) },
+	func(b *board) (Step, bool) { return findNakedSubset(b, 3) },
+	func(b *board) (Step, bool) { return findHiddenSubset(b, 3) },
+	func(b *board) (Step, bool) { return findFish(b, 2) },
+	func(b *board) (Step, bool) { return findFish(b, 3) },
+	findXYWing,
+	findXYZWing,
+}
+
+// Find the easiest step that makes progress
+func (b *board) nextStep() (Step, bool) {
+	for _, find := range techniques {
+		if step, ok := find(b); ok {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// A digit that can only go in one cell of a unit
+func findHiddenSingle(b *board) (Step, bool) {
+	for u, cells := range b.lay.unitCells {
+		for d := 1; d <= b.lay.size; d++ {
+			bit := digitBit(d)
+			pos, count := -1, 0
+			for _, c := range cells {
+				if b.cands[c]&bit != 0 {
+					pos = c
+					count++
+				}
+			}
+			if count == 1 {
+				return Step{
+					Technique:  HiddenSingle,
+					Units:      b.unitList(u),
+					Placements: []Candidate{b.candidate(pos, d)},
+				}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// A cell with only one candidate left
+func findNakedSingle(b *board) (Step, bool) {
+	for idx, mask := range b.cands {
+		if b.values[idx] == 0 && bits.OnesCount16(mask) == 1 {
+			return Step{
+				Technique:  NakedSingle,
+				Placements: []Candidate{b.candidate(idx, bits.TrailingZeros16(mask)+1)},
+			}, true
+		}
+	}
+	return Step{}, false
+}
+
+// A digit whose candidates in one unit all lie in a second unit can be
+// removed from the rest of the second unit. Starting from a box, jigsaw
+// region or window this is a pointing pair/triple, starting from a line it
+// is box/line reduction.
+func findLockedCandidates(b *board, fromBox bool) (Step, bool) {
+	for u, cells := range b.lay.unitCells {
+		kind := b.lay.units[u].Kind
+		if (kind == BoxUnit || kind == RegionUnit || kind == WindowUnit) != fromBox {
+			continue
+		}
+
+		for d := 1; d <= b.lay.size; d++ {
+			bit := digitBit(d)
+			var pos []int
+			for _, c := range cells {
+				if b.cands[c]&bit != 0 {
+					pos = append(pos, c)
+				}
+			}
+			if len(pos) < 2 {
+				continue
+			}
+
+			for _, other := range b.lay.cellUnits[pos[0]] {
+				if other == u {
+					continue
+				}
+				shared := true
+				for _, c := range pos[1:] {
+					if !b.inUnit(c, other) {
+						shared = false
+						break
+					}
+				}
+				if !shared {
+					continue
+				}
+
+				var elims []Candidate
+				for _, c := range b.lay.unitCells[other] {
+					if b.cands[c]&bit != 0 && !b.inUnit(c, u) {
+						elims = append(elims, b.candidate(c, d))
+					}
+				}
+				if len(elims) > 0 {
+					technique := BoxLineReduction
+					if fromBox {
+						technique = Pointing
+					}
+					return Step{
+						Technique:    technique,
+						Units:        b.unitList(u, other),
+						Digits:       []int{d},
+						Cells:        b.cellList(pos),
+						Eliminations: elims,
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// Candidates of a killer cage that take part in no set of different
+// digits adding up to what is left of the cage's sum
+func findCageCombination(b *board) (Step, bool) {
+	for k, cells := range b.cages {
+		left := b.cageSums[k]
+		var used uint16
+		var empty []int
+		for _, c := range cells {
+			if v := b.values[c]; v != 0 {
+				left -= v
+				used |= digitBit(v)
+			} else {
+				empty = append(empty, c)
+			}
+		}
+		if len(empty) == 0 {
+			continue
+		}
+
+		// Try every way to fill the empty cells, noting the digits each
+		// cell takes in at least one of them
+		possible := make([]uint16, len(empty))
+		var fill func(i int, used uint16, left int) bool
+		fill = func(i int, used uint16, left int) bool {
+			if i == len(empty) {
+				return left == 0
+			}
+			if cageDigits(allDigits&^used, len(empty)-i, left) == 0 {
+				return false
+			}
+			found := false
+			for cands := b.cands[empty[i]] &^ used; cands != 0; cands &= cands - 1 {
+				d := bits.TrailingZeros16(cands) + 1
+				if fill(i+1, used|digitBit(d), left-d) {
+					possible[i] |= digitBit(d)
+					found = true
+				}
+			}
+			return found
+		}
+		fill(0, used, left)
+
+		var elims []Candidate
+		for i, c := range empty {
+			for _, d := range maskDigits(b.cands[c] &^ possible[i]) {
+				elims = append(elims, b.candidate(c, d))
+			}
+		}
+		if len(elims) > 0 {
+			return Step{
+				Technique:    CageCombination,
+				Units:        []Unit{{CageUnit, k}},
+				Cells:        b.cellList(empty),
+				Eliminations: elims,
+			}, true
+		}
+	}
+	return Step{}, false
+}
+
+// k cells of a unit that together hold only k candidates
+func findNakedSubset(b *board, k int) (Step, bool) {
+	technique := NakedPair
+	if k == 3 {
+		technique = NakedTriple
+	}
+
+	for u, cells := range b.lay.unitCells {
+		var open []int
+		for _, c := range cells {
+			n := bits.OnesCount16(b.cands[c])
+			if b.values[c] == 0 && n >= 2 && n <= k {
+				open = append(open, c)
+			}
+		}
+		if len(open) < k {
+			continue
+		}
+
+		var step Step
+		found := combinations(len(open), k, func(pick []int) bool {
+			var union uint16
+			subset := make([]int, k)
+			for i, p := range pick {
+				subset[i] = open[p]
+				union |= b.cands[open[p]]
+			}
+			if bits.OnesCount16(union) != k {
+				return false
+			}
+
+			var elims []Candidate
+			for _, c := range cells {
+				if b.cands[c]&union == 0 || contains(subset, c) {
+					continue
+				}
+				for _, d := range maskDigits(b.cands[c] & union) {
+					elims = append(elims, b.candidate(c, d))
+				}
+			}
+			if len(elims) == 0 {
+				return false
+			}
+
+			step = Step{
+				Technique:    technique,
+				Units:        b.unitList(u),
+				Digits:       maskDigits(union),
+				Cells:        b.cellList(subset),
+				Eliminations: elims,
+			}
+			return true
+		})
+		if found {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// k digits of a unit that are confined to the same k cells
+func findHiddenSubset(b *board, k int) (Step, bool) {
+	technique := HiddenPair
+	if k == 3 {
+		technique = HiddenTriple
+	}
+
+	for u, cells := range b.lay.unitCells {
+		// Positions (as a mask over the unit's cells) of each open digit
+		var digits []int
+		var positions []uint32
+		for d := 1; d <= b.lay.size; d++ {
+			var mask uint32
+			for i, c := range cells {
+				if b.cands[c]&digitBit(d) != 0 {
+					mask |= 1 << i
+				}
+			}
+			if n := bits.OnesCount32(mask); n >= 2 && n <= k {
+				digits = append(digits, d)
+				positions = append(positions, mask)
+			}
+		}
+		if len(digits) < k {
+			continue
+		}
+
+		var step Step
+		found := combinations(len(digits), k, func(pick []int) bool {
+			var union uint32
+			var digitMask uint16
+			for _, p := range pick {
+				union |= positions[p]
+				digitMask |= digitBit(digits[p])
+			}
+			if bits.OnesCount32(union) != k {
+				return false
+			}
+
+			var subset []int
+			var elims []Candidate
+			for i, c := range cells {
+				if union&(1<<i) == 0 {
+					continue
+				}
+				subset = append(subset, c)
+				for _, d := range maskDigits(b.cands[c] &^ digitMask) {
+					elims = append(elims, b.candidate(c, d))
+				}
+			}
+			if len(elims) == 0 {
+				return false
+			}
+
+			step = Step{
+				Technique:    technique,
+				Units:        b.unitList(u),
+				Digits:       maskDigits(digitMask),
+				Cells:        b.cellList(subset),
+				Eliminations: elims,
+			}
+			return true
+		})
+		if found {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// X-Wing (k=2) and Swordfish (k=3): a digit confined to the same k columns
+// in k rows can be removed from the rest of those columns, and vice versa
+func findFish(b *board, k int) (Step, bool) {
+	technique := XWing
+	if k == 3 {
+		technique = Swordfish
+	}
+
+	for d := 1; d <= b.lay.size; d++ {
+		bit := digitBit(d)
+
+		for _, lines := range [2][2][]int{{b.lay.rows, b.lay.cols}, {b.lay.cols, b.lay.rows}} {
+			base, cover := lines[0], lines[1]
+
+			var baseUnits []int
+			var positions []uint32
+			for _, u := range base {
+				var mask uint32
+				for i, c := range b.lay.unitCells[u] {
+					if b.cands[c]&bit != 0 {
+						mask |= 1 << i
+					}
+				}
+				if n := bits.OnesCount32(mask); n >= 2 && n <= k {
+					baseUnits = append(baseUnits, u)
+					positions = append(positions, mask)
+				}
+			}
+			if len(baseUnits) < k {
+				continue
+			}
+
+			var step Step
+			found := combinations(len(baseUnits), k, func(pick []int) bool {
+				var union uint32
+				chosen := make([]int, k)
+				for i, p := range pick {
+					union |= positions[p]
+					chosen[i] = baseUnits[p]
+				}
+				if bits.OnesCount32(union) != k {
+					return false
+				}
+
+				var pattern []int
+				for _, u := range chosen {
+					for _, c := range b.lay.unitCells[u] {
+						if b.cands[c]&bit != 0 {
+							pattern = append(pattern, c)
+						}
+					}
+				}
+
+				var elims []Candidate
+				for i := range cover {
+					if union&(1<<i) == 0 {
+						continue
+					}
+					for _, c := range b.lay.unitCells[cover[i]] {
+						if b.cands[c]&bit != 0 && !contains(pattern, c) {
+							elims = append(elims, b.candidate(c, d))
+						}
+					}
+				}
+				if len(elims) == 0 {
+					return false
+				}
+
+				step = Step{
+					Technique:    technique,
+					Units:        b.unitList(chosen...),
+					Digits:       []int{d},
+					Cells:        b.cellList(pattern),
+					Eliminations: elims,
+				}
+				return true
+			})
+			if found {
+				return step, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// A bivalue pivot {x,y} seeing pincers {x,z} and {y,z}: z can be removed
+// from every cell that sees both pincers
+func findXYWing(b *board) (Step, bool) {
+	for pivot, pm := range b.cands {
+		if bits.OnesCount16(pm) != 2 {
+			continue
+		}
+
+		for _, a := range b.lay.peers[pivot] {
+			am := b.cands[a]
+			if bits.OnesCount16(am) != 2 || bits.OnesCount16(am&pm) != 1 {
+				continue
+			}
+			z := am &^ pm
+			want := (pm &^ am) | z
+
+			for _, c := range b.lay.peers[pivot] {
+				if c == a || b.cands[c] != want {
+					continue
+				}
+
+				elims := b.commonPeerElims(z, pivot, a, c)
+				if len(elims) > 0 {
+					return Step{
+						Technique:    XYWing,
+						Digits:       maskDigits(pm | z),
+						Cells:        b.cellList([]int{pivot, a, c}),
+						Eliminations: elims,
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// A pivot {x,y,z} seeing pincers {x,z} and {y,z}: z can be removed from
+// every cell that sees the pivot and both pincers
+func findXYZWing(b *board) (Step, bool) {
+	for pivot, pm := range b.cands {
+		if bits.OnesCount16(pm) != 3 {
+			continue
+		}
+
+		for _, a := range b.lay.peers[pivot] {
+			am := b.cands[a]
+			if bits.OnesCount16(am) != 2 || am&^pm != 0 {
+				continue
+			}
+
+			for _, c := range b.lay.peers[pivot] {
+				cm := b.cands[c]
+				if c == a || bits.OnesCount16(cm) != 2 || cm&^pm != 0 || am|cm != pm {
+					continue
+				}
+				z := am & cm
+
+				elims := b.commonPeerElims(z, pivot, a, c)
+				var seenByPivot []Candidate
+				for _, e := range elims {
+					if b.lay.isPeer[pivot][e.Row*b.lay.size+e.Col] {
+						seenByPivot = append(seenByPivot, e)
+					}
+				}
+				if len(seenByPivot) > 0 {
+					return Step{
+						Technique:    XYZWing,
+						Digits:       maskDigits(pm),
+						Cells:        b.cellList([]int{pivot, a, c}),
+						Eliminations: seenByPivot,
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// Candidates of the digits in mask held by cells that see both pincers
+// of a wing
+func (b *board) commonPeerElims(mask uint16, pivot, a, c int) []Candidate {
+	var elims []Candidate
+	for _, p := range b.lay.peers[a] {
+		if p == pivot || p == c || !b.lay.isPeer[c][p] || b.cands[p]&mask == 0 {
+			continue
+		}
+		for _, d := range maskDigits(b.cands[p] & mask) {
+			elims = append(elims, b.candidate(p, d))
+		}
+	}
+	return elims
+}
+
+// Call fn with each k-element combination of 0..n-1 until it returns true
+func combinations(n, k int, fn func(pick []int) bool) bool {
+	pick := make([]int, k)
+	var rec func(start, depth int) bool
+	rec = func(start, depth int) bool {
+		if depth == k {
+			return fn(pick)
+		}
+		for i := start; i <= n-(k-depth); i++ {
+			pick[depth] = i
+			if rec(i+1, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+	return rec(0, 0)
+}
+
+func contains(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package sudoku
+
+import "testing"
+
+// Helper: parse an 81-character puzzle string with 0 or . for blanks
+func parseTestGrid(t testing.TB, s string) Grid {
+	t.Helper()
+	if len(s) != 81 {
+		t.Fatalf("puzzle has %d characters, want 81", len(s))
+	}
+	var grid Grid
+	for i, ch := range s {
+		if ch >= '1' && ch <= '9' {
+			grid[i/9][i%9] = int(ch - '0')
+		}
+	}
+	return grid
+}
+
+// Helper: a board with no givens and every candidate still open
+func emptyTestBoard() *board {
+	var grid Grid
+	b, _ := newBoard(classicLayout, &grid)
+	return b
+}
+
+func TestSolveLogicallySingles(t *testing.T) {
+	grid := parseTestGrid(t, "003020600900305001001806400008102900700000008006708200002609500800203009005010300")
+
+	result := SolveLogically(grid)
+	if !result.Solved {
+		t.Fatalf("puzzle not solved, stopped after %d steps", len(result.Steps))
+	}
+	if !isValidSudokuGrid(&result.Grid) {
+		t.Fatal("logical solver produced invalid grid")
+	}
+	for _, step := range result.Steps {
+		if step.Technique != HiddenSingle && step.Technique != NakedSingle {
+			t.Errorf("easy puzzle needed %s: %s", step.Technique, step)
+		}
+	}
+}
+
+func TestSolveLogicallyStepsAreSound(t *testing.T) {
+	for _, d := range []Difficulty{Easy, Medium, Hard, Expert} {
+		for seed := range int64(3) {
+			s := NewWithSeed(d, seed)
+			result := SolveLogically(s.Grid)
+
+			for _, step := range result.Steps {
+				for _, p := range step.Placements {
+					if s.Solution[p.Row][p.Col] != p.Digit {
+						t.Fatalf("%s: wrong placement %s", d, step)
+					}
+				}
+				for _, e := range step.Eliminations {
+					if s.Solution[e.Row][e.Col] == e.Digit {
+						t.Fatalf("%s: eliminated solution digit in %s", d, step)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSolveLogicallyRejectsConflictingGivens(t *testing.T) {
+	var grid Grid
+	grid[0][0] = 5
+	grid[0][8] = 5
+
+	if result := SolveLogically(grid); result.Solved || len(result.Steps) > 0 {
+		t.Fatal("expected no progress on a grid with conflicting givens")
+	}
+}
+
+func TestFindNakedPair(t *testing.T) {
+	b := emptyTestBoard()
+	b.cands[0] = digitBit(3) | digitBit(7)
+	b.cands[1] = digitBit(3) | digitBit(7)
+
+	step, ok := findNakedSubset(b, 2)
+	if !ok {
+		t.Fatal("naked pair not found")
+	}
+	if step.Units[0] != (Unit{BoxUnit, 0}) {
+		t.Errorf("found in %s, want box 1", step.Units[0])
+	}
+	if len(step.Eliminations) != 14 {
+		t.Errorf("got %d eliminations, want 14", len(step.Eliminations))
+	}
+}
+
+func TestFindXWing(t *testing.T) {
+	b := emptyTestBoard()
+	for _, row := range []int{1, 6} {
+		for col := range 9 {
+			if col != 2 && col != 7 {
+				b.cands[row*9+col] &^= digitBit(5)
+			}
+		}
+	}
+
+	step, ok := findFish(b, 2)
+	if !ok {
+		t.Fatal("X-Wing not found")
+	}
+	if step.Digits[0] != 5 || step.Units[0] != (Unit{RowUnit, 1}) || step.Units[1] != (Unit{RowUnit, 6}) {
+		t.Errorf("unexpected pattern: %s", step)
+	}
+	for _, e := range step.Eliminations {
+		if e.Col != 2 && e.Col != 7 || e.Row == 1 || e.Row == 6 {
+			t.Errorf("unexpected elimination %s<>%d", e.Cell, e.Digit)
+		}
+	}
+	if len(step.Eliminations) != 14 {
+		t.Errorf("got %d eliminations, want 14", len(step.Eliminations))
+	}
+}
+
+func TestFindXYWing(t *testing.T) {
+	b := emptyTestBoard()
+	b.cands[0] = digitBit(1) | digitBit(2)   // r1c1 pivot
+	b.cands[5] = digitBit(1) | digitBit(3)   // r1c6 pincer
+	b.cands[5*9] = digitBit(2) | digitBit(3) // r6c1 pincer
+
+	step, ok := findXYWing(b)
+	if !ok {
+		t.Fatal("XY-Wing not found")
+	}
+	if len(step.Eliminations) != 1 || step.Eliminations[0] != (Candidate{Cell{5, 5}, 3}) {
+		t.Errorf("unexpected eliminations: %s", step)
+	}
+}
+
+func TestStepString(t *testing.T) {
+	step := Step{
+		Technique:  HiddenSingle,
+		Units:      []Unit{{BoxUnit, 4}},
+		Placements: []Candidate{{Cell{3, 4}, 7}},
+	}
+	if got, want := step.String(), "Hidden single in box 5: r4c5=7"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNextPlacement(t *testing.T) {
+	s := NewWithSeed(Hard, 2)
+
+	steps, ok := NextPlacement(s.Grid)
+	if !ok {
+		t.Fatal("no placement found")
+	}
+	for _, step := range steps[:len(steps)-1] {
+		if len(step.Placements) > 0 {
+			t.Errorf("placement before the last step: %s", step)
+		}
+	}
+
+	p := steps[len(steps)-1].Placements[0]
+	if s.Grid[p.Row][p.Col] != 0 || s.Solution[p.Row][p.Col] != p.Digit {
+		t.Errorf("bad placement %s=%d", p.Cell, p.Digit)
+	}
+
+	if _, ok := NextPlacement(s.Solution); ok {
+		t.Error("found a placement in a solved grid")
+	}
+}
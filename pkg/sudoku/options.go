@@ -0,0 +1,215 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Symmetry of the pattern of givens in a generated puzzle
+type Symmetry int
+
+const (
+	SymmetryRotate180 Symmetry = iota // Half-turn about the centre, the classic look
+	SymmetryRotate90                  // Quarter-turn about the centre
+	SymmetryDiagonal                  // Mirror across the main diagonal
+	SymmetryMirror                    // Mirror across the centre column
+	SymmetryNone
+)
+
+var symmetryNames = []string{"rotate180", "rotate90", "diagonal", "mirror", "none"}
+
+func (s Symmetry) String() string {
+	if s < 0 || int(s) >= len(symmetryNames) {
+		return "unknown"
+	}
+	return symmetryNames[s]
+}
+
+// Parse a symmetry name such as "mirror", ignoring case
+func ParseSymmetry(name string) (Symmetry, error) {
+	for s, n := range symmetryNames {
+		if strings.EqualFold(name, n) {
+			return Symmetry(s), nil
+		}
+	}
+	return SymmetryRotate180, fmt.Errorf("unknown symmetry %q", name)
+}
+
+// Cells of a board of the given size that must be cleared together with a
+// cell to keep the symmetry, starting with the cell itself
+func (s Symmetry) orbit(size, row, col int) []Cell {
+	last := size - 1
+	orbit := []Cell{{row, col}}
+	add := func(r, c int) {
+		for _, o := range orbit {
+			if o.Row == r && o.Col == c {
+				return
+			}
+		}
+		orbit = append(orbit, Cell{r, c})
+	}
+
+	switch s {
+	case SymmetryRotate180:
+		add(last-row, last-col)
+	case SymmetryRotate90:
+		add(col, last-row)
+		add(last-row, last-col)
+		add(last-col, row)
+	case SymmetryDiagonal:
+		add(col, row)
+	case SymmetryMirror:
+		add(row, last-col)
+	}
+	return orbit
+}
+
+// Option adjusts how Generate builds a puzzle
+type Option func(*options)
+
+type options struct {
+	seed     int64
+	seeded   bool
+	solver   Solver
+	symmetry Symmetry
+	minClues int // 0 when the difficulty picks the clue count
+	maxClues int
+	minScore int
+	maxScore int
+	scoreSet bool
+	rules    Rules
+	size     int  // 0 for 9x9
+	jigsaw   bool // Draw random regions for every candidate
+	killer   bool
+	ctx      context.Context
+	timeout  time.Duration
+}
+
+// Generate from a fixed seed, so the same options give the same puzzle
+func WithSeed(seed int64) Option {
+	return func(o *options) { o.seed, o.seeded = seed, true }
+}
+
+// Check uniqueness with another solver engine
+func WithSolver(s Solver) Option {
+	return func(o *options) { o.solver = s }
+}
+
+// Arrange the givens with a symmetry. The default is SymmetryRotate180.
+func WithSymmetry(s Symmetry) Option {
+	return func(o *options) { o.symmetry = s }
+}
+
+// Generate a puzzle with exactly n givens
+func WithClues(n int) Option {
+	return WithClueRange(n, n)
+}
+
+// Generate a puzzle with between lo and hi givens
+func WithClueRange(lo, hi int) Option {
+	return func(o *options) { o.minClues, o.maxClues = lo, max(lo, hi) }
+}
+
+// Aim for a rating score instead of the difficulty's band. The number of
+// givens then follows the band the score falls in.
+func WithTargetScore(score int) Option {
+	return WithScoreRange(score, score)
+}
+
+// Accept rating scores from lo to hi instead of the difficulty's band
+func WithScoreRange(lo, hi int) Option {
+	return func(o *options) { o.minScore, o.maxScore, o.scoreSet = lo, max(lo, hi), true }
+}
+
+// Generate a classic puzzle on a board of another size: 4, 6, 12 or 16.
+// Ratings, clue counts and scores are tuned for 9x9, so on other sizes the
+// difficulty only sets the share of cells cleared.
+func WithSize(n int) Option {
+	return func(o *options) { o.size = n }
+}
+
+// Generate a jigsaw puzzle on the given regions
+func WithRegions(r Regions) Option {
+	return func(o *options) { o.rules.Regions, o.jigsaw = &r, false }
+}
+
+// Generate a jigsaw puzzle on random irregular regions
+func WithJigsaw() Option {
+	return func(o *options) { o.rules.Regions, o.jigsaw = nil, true }
+}
+
+// Generate a Sudoku X, whose two main diagonals also hold every digit
+func WithDiagonals() Option {
+	return func(o *options) { o.rules.Diagonals = true }
+}
+
+// Generate a Hyper Sudoku, whose four extra 3x3 windows also hold every
+// digit
+func WithWindows() Option {
+	return func(o *options) { o.rules.Windows = true }
+}
+
+// Generate a killer puzzle with random cages. The difficulty sets the
+// cage sizes and how many givens are revealed up front; symmetry, clue
+// and score options do not apply.
+func WithKiller() Option {
+	return func(o *options) { o.killer = true }
+}
+
+// Stop generating when the context is cancelled
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// Stop generating after a time limit
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// Generate a puzzle for a difficulty, adjusted by options. Without a seed
+// a random one is picked. When no candidate meets every constraint the
+// closest one is returned, favouring the clue count over the rating. It
+// fails when generation is cancelled or times out, or when the regions
+// given for a jigsaw are invalid or cannot be filled.
+func Generate(difficulty Difficulty, opts ...Option) (Sudoku, error) {
+	o := options{
+		solver:   DefaultSolver,
+		symmetry: SymmetryRotate180,
+		ctx:      context.Background(),
+	}
+	o.minScore, o.maxScore = difficulty.ScoreRange()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.scoreSet {
+		difficulty = min(max(Difficulty(o.minScore/bandSize), Easy), Expert)
+	}
+	if o.solver == nil {
+		o.solver = DefaultSolver
+	}
+	if !o.seeded {
+		o.seed = RandomSeed()
+	}
+	if o.size != 0 {
+		sized, err := SizedRules(o.size)
+		if err != nil {
+			return Sudoku{}, err
+		}
+		o.rules.Box = sized.Box
+		if o.size != 9 && (o.jigsaw || o.killer) {
+			return Sudoku{}, fmt.Errorf("jigsaw and killer puzzles are only played on 9x9 boards")
+		}
+	}
+	if err := o.rules.Validate(); err != nil {
+		return Sudoku{}, err
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		o.ctx, cancel = context.WithTimeout(o.ctx, o.timeout)
+		defer cancel()
+	}
+
+	return generateWith(difficulty, &o)
+}
@@ -0,0 +1,86 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateSymmetry(t *testing.T) {
+	for s := SymmetryRotate180; s <= SymmetryNone; s++ {
+		t.Run(s.String(), func(t *testing.T) {
+			puzzle, err := Generate(Medium, WithSeed(1), WithSymmetry(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := countPuzzleSolutions(puzzle.Grid); n != 1 {
+				t.Fatalf("puzzle has %d solutions", n)
+			}
+			if s == SymmetryNone {
+				return
+			}
+
+			for i := range 9 {
+				for j := range 9 {
+					for _, o := range s.orbit(9, i, j)[1:] {
+						if (puzzle.Grid[i][j] == 0) != (puzzle.Grid[o.Row][o.Col] == 0) {
+							t.Fatalf("%s and %s break the symmetry:\n%s", Cell{i, j}, o, puzzle.Grid)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateClues(t *testing.T) {
+	puzzle, err := Generate(Medium, WithSeed(2), WithClues(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := puzzle.Grid.Clues(); n != 30 {
+		t.Errorf("got %d clues, want 30", n)
+	}
+
+	puzzle, err = Generate(Hard, WithSeed(2), WithClueRange(24, 26), WithSymmetry(SymmetryNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := puzzle.Grid.Clues(); n < 24 || n > 26 {
+		t.Errorf("got %d clues, want 24-26", n)
+	}
+}
+
+func TestGenerateTargetScore(t *testing.T) {
+	puzzle, err := Generate(Easy, WithSeed(3), WithScoreRange(120, 160))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score := Grade(puzzle.Grid).Score; score < 120 || score > 160 {
+		t.Errorf("score %d, want 120-160", score)
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Generate(Expert, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+
+	if _, err := Generate(Expert, WithTimeout(time.Nanosecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestGenerateMatchesGenerator(t *testing.T) {
+	puzzle, err := Generate(Hard, WithSeed(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(puzzle, NewWithSeed(Hard, 4)) {
+		t.Error("default options differ from NewWithSeed")
+	}
+}
@@ -0,0 +1,193 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Regions assigns every cell to one of nine regions of nine cells, by
+// 0-based region index. Classic puzzles use the 3x3 boxes; jigsaw puzzles
+// use irregular shapes.
+type Regions [9][9]int
+
+// Successful swaps used to reshape the boxes into a jigsaw layout
+const regionSwaps = 30
+
+func boxIndex(row, col int) int {
+	return (row/3)*3 + col/3
+}
+
+// The 3x3 boxes of a classic puzzle
+func BoxRegions() Regions {
+	var r Regions
+	for i := range r {
+		for j := range r[i] {
+			r[i][j] = boxIndex(i, j)
+		}
+	}
+	return r
+}
+
+// Generate a random jigsaw layout. The same seed always gives the same
+// layout.
+func RandomRegions(seed int64) Regions {
+	return randomRegions(rand.New(rand.NewSource(seed)))
+}
+
+// Reshape the boxes by repeatedly handing a cell to a neighbouring region
+// and taking one back, keeping both regions connected
+func randomRegions(rng *rand.Rand) Regions {
+	r := BoxRegions()
+
+	for swaps, attempts := 0, 0; swaps < regionSwaps && attempts < regionSwaps*100; attempts++ {
+		a := Cell{rng.Intn(9), rng.Intn(9)}
+		from := r[a.Row][a.Col]
+		to, ok := r.otherNeighbour(rng, a)
+		if !ok {
+			continue
+		}
+
+		// Pick a cell of the other region that borders the first one
+		var back []Cell
+		for i := range r {
+			for j := range r[i] {
+				c := Cell{i, j}
+				if r[i][j] == to && c != a && r.borders(c, from) {
+					back = append(back, c)
+				}
+			}
+		}
+		if len(back) == 0 {
+			continue
+		}
+		b := back[rng.Intn(len(back))]
+
+		r[a.Row][a.Col], r[b.Row][b.Col] = to, from
+		if r.connected(from) && r.connected(to) {
+			swaps++
+		} else {
+			r[a.Row][a.Col], r[b.Row][b.Col] = from, to
+		}
+	}
+	return r
+}
+
+// Orthogonal neighbours of a cell that lie on the board
+func neighbours(c Cell) []Cell {
+	var cells []Cell
+	for _, d := range [4]Cell{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		n := Cell{c.Row + d.Row, c.Col + d.Col}
+		if n.Row >= 0 && n.Row < 9 && n.Col >= 0 && n.Col < 9 {
+			cells = append(cells, n)
+		}
+	}
+	return cells
+}
+
+// Pick the region of a random neighbour outside the cell's own region
+func (r *Regions) otherNeighbour(rng *rand.Rand, c Cell) (int, bool) {
+	var regions []int
+	for _, n := range neighbours(c) {
+		if r[n.Row][n.Col] != r[c.Row][c.Col] {
+			regions = append(regions, r[n.Row][n.Col])
+		}
+	}
+	if len(regions) == 0 {
+		return 0, false
+	}
+	return regions[rng.Intn(len(regions))], true
+}
+
+// Check whether a cell touches a region
+func (r *Regions) borders(c Cell, reg int) bool {
+	for _, n := range neighbours(c) {
+		if r[n.Row][n.Col] == reg {
+			return true
+		}
+	}
+	return false
+}
+
+// Check that the cells of a region form one orthogonally connected shape
+func (r *Regions) connected(reg int) bool {
+	var seen [9][9]bool
+	var stack []Cell
+	total := 0
+	for i := range r {
+		for j := range r[i] {
+			if r[i][j] != reg {
+				continue
+			}
+			total++
+			if len(stack) == 0 && !seen[i][j] {
+				seen[i][j] = true
+				stack = append(stack, Cell{i, j})
+			}
+		}
+	}
+
+	reached := 0
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		reached++
+		for _, n := range neighbours(c) {
+			if r[n.Row][n.Col] == reg && !seen[n.Row][n.Col] {
+				seen[n.Row][n.Col] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return reached == total
+}
+
+// Check that there are nine connected regions of nine cells each
+func (r Regions) Validate() error {
+	var sizes [9]int
+	for i := range r {
+		for j, reg := range r[i] {
+			if reg < 0 || reg > 8 {
+				return fmt.Errorf("region %d at %s is out of range", reg+1, Cell{i, j})
+			}
+			sizes[reg]++
+		}
+	}
+	for reg, n := range sizes {
+		if n != 9 {
+			return fmt.Errorf("region %d has %d cells, want 9", reg+1, n)
+		}
+		if !r.connected(reg) {
+			return fmt.Errorf("region %d is not connected", reg+1)
+		}
+	}
+	return nil
+}
+
+// Write the regions as an 81 character line of region numbers 1-9
+func (r Regions) String() string {
+	var b strings.Builder
+	for i := range r {
+		for _, reg := range r[i] {
+			b.WriteByte(byte('1' + reg))
+		}
+	}
+	return b.String()
+}
+
+// Read regions written by String. Whitespace is ignored, so the layout can
+// also be written as nine lines.
+func ParseRegions(data string) (Regions, error) {
+	var r Regions
+	cells := strings.Join(strings.Fields(data), "")
+	if len(cells) != 81 {
+		return r, fmt.Errorf("regions have %d cells, want 81", len(cells))
+	}
+	for idx, ch := range []byte(cells) {
+		if ch < '1' || ch > '9' {
+			return r, fmt.Errorf("invalid region %q at %s", ch, classicLayout.cell(idx))
+		}
+		r[idx/9][idx%9] = int(ch - '1')
+	}
+	return r, r.Validate()
+}
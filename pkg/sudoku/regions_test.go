@@ -0,0 +1,111 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRandomRegions(t *testing.T) {
+	boxes := BoxRegions()
+	for seed := range int64(20) {
+		r := RandomRegions(seed)
+		if err := r.Validate(); err != nil {
+			t.Fatalf("seed %d: %v\n%s", seed, err, r)
+		}
+		if r == boxes {
+			t.Errorf("seed %d: regions are still the boxes", seed)
+		}
+		if RandomRegions(seed) != r {
+			t.Errorf("seed %d: regions differ between runs", seed)
+		}
+	}
+}
+
+func TestParseRegions(t *testing.T) {
+	r := RandomRegions(1)
+	got, err := ParseRegions(r.String())
+	if err != nil || got != r {
+		t.Fatalf("round trip gave %s, %v", got, err)
+	}
+
+	for name, data := range map[string]string{
+		"short":        strings.Repeat("1", 80),
+		"bad digit":    "0" + BoxRegions().String()[1:],
+		"wrong size":   "2" + BoxRegions().String()[1:],
+		"disconnected": "111222333111222333111222333444555666444555666444555666777888999777888999777889998",
+	} {
+		if _, err := ParseRegions(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestGenerateJigsaw(t *testing.T) {
+	s, err := Generate(Easy, WithSeed(5), WithJigsaw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Rules.Jigsaw() || *s.Rules.Regions == BoxRegions() {
+		t.Fatal("puzzle does not use irregular regions")
+	}
+	if !s.Rules.HasUniqueSolution(s.Grid) {
+		t.Fatal("puzzle is not unique under its regions")
+	}
+
+	// The solution must hold every digit once per region
+	for _, cells := range s.Rules.layout().unitCells {
+		var seen uint16
+		for _, c := range cells {
+			seen |= digitBit(s.Solution[c/9][c%9])
+		}
+		if seen != allDigits {
+			t.Fatalf("solution repeats a digit in a unit:\n%s\n%s", s.Solution, s.Rules.Regions)
+		}
+	}
+
+	if again, _ := Generate(Easy, WithSeed(5), WithJigsaw()); again.Grid != s.Grid || *again.Rules.Regions != *s.Rules.Regions {
+		t.Error("the same seed gave a different jigsaw")
+	}
+}
+
+func TestGenerateWithRegions(t *testing.T) {
+	regions := RandomRegions(2)
+	s, err := Generate(Easy, WithSeed(1), WithRegions(regions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *s.Rules.Regions != regions {
+		t.Error("puzzle does not use the given regions")
+	}
+	for _, solver := range []Solver{BacktrackSolver{Rules: s.Rules}, DLXSolver{Rules: s.Rules}} {
+		if n := solver.CountSolutions(s.Grid, 2); n != 1 {
+			t.Errorf("%T counts %d solutions", solver, n)
+		}
+		if got, ok := solver.Solve(s.Grid); !ok || got != s.Solution {
+			t.Errorf("%T solved to a different grid", solver)
+		}
+	}
+
+	regions[0][0] = 8
+	if _, err := Generate(Easy, WithRegions(regions)); err == nil {
+		t.Error("expected an error for invalid regions")
+	}
+}
+
+func TestJigsawExportRoundTrip(t *testing.T) {
+	s, err := Generate(Easy, WithSeed(3), WithJigsaw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Export(FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Import(data, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rules.Regions == nil || *got.Rules.Regions != *s.Rules.Regions || got.Solution != s.Solution {
+		t.Error("regions or solution lost in the JSON round trip")
+	}
+}
@@ -0,0 +1,211 @@
+package sudoku
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Rules describe the size of the board and the units a puzzle is played on
+// besides its rows and columns. The zero value is classic 9x9 Sudoku with
+// 3x3 boxes. Jigsaw, killer, X and Hyper puzzles are only played on 9x9.
+type Rules struct {
+	Box       BoxShape // Shape of the boxes, which sets the board size; zero for 3x3
+	Regions   *Regions // Irregular regions of a jigsaw puzzle; nil for 3x3 boxes
+	Cages     []Cage   // Sum cages of a killer puzzle
+	Diagonals bool     // Both main diagonals hold every digit, as in Sudoku X
+	Windows   bool     // Four extra 3x3 windows hold every digit, as in Hyper Sudoku
+}
+
+// Rules of a jigsaw puzzle on the given regions
+func JigsawRules(regions Regions) Rules {
+	return Rules{Regions: &regions}
+}
+
+// Rules of a classic puzzle on a board of the given size. For 9x9 that is
+// the zero value.
+func SizedRules(size int) (Rules, error) {
+	box, err := BoxShapeFor(size)
+	if err != nil || size == 9 {
+		return Rules{}, err
+	}
+	return Rules{Box: box}, nil
+}
+
+// Shape of the boxes, 3x3 when not set
+func (r Rules) BoxShape() BoxShape {
+	if r.Box == (BoxShape{}) {
+		return boxShapes[9]
+	}
+	return r.Box
+}
+
+// Number of rows, columns and digits of the board
+func (r Rules) Size() int {
+	return r.BoxShape().Size()
+}
+
+// Check if the rules use irregular regions
+func (r Rules) Jigsaw() bool {
+	return r.Regions != nil
+}
+
+// Check if the rules have killer cages
+func (r Rules) Killer() bool {
+	return len(r.Cages) > 0
+}
+
+// Check that the board size is supported, that the regions are well
+// formed and that the cages fit on the board without overlapping
+func (r Rules) Validate() error {
+	size := r.Size()
+	box, err := BoxShapeFor(size)
+	if err != nil {
+		return err
+	}
+	if box != r.BoxShape() {
+		return fmt.Errorf("%s boxes do not fit a %dx%d board", r.Box, size, size)
+	}
+	if size != 9 && (r.Regions != nil || len(r.Cages) > 0 || r.Diagonals || r.Windows) {
+		return fmt.Errorf("jigsaw, killer, X and Hyper puzzles are only played on 9x9 boards")
+	}
+
+	if r.Regions != nil {
+		if err := r.Regions.Validate(); err != nil {
+			return err
+		}
+	}
+
+	var covered [9][9]bool
+	for k, c := range r.Cages {
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("cage %d: %w", k+1, err)
+		}
+		for _, cell := range c.Cells {
+			if covered[cell.Row][cell.Col] {
+				return fmt.Errorf("cage %d: %s is already in a cage", k+1, cell)
+			}
+			covered[cell.Row][cell.Col] = true
+		}
+	}
+	return nil
+}
+
+// Find the index of the cage holding a cell
+func (r Rules) CageOf(row, col int) (int, bool) {
+	for k, c := range r.Cages {
+		for _, cell := range c.Cells {
+			if cell.Row == row && cell.Col == col {
+				return k, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Diagonals and windows a cell lies in
+func (r Rules) ExtraUnits(row, col int) []Unit {
+	lay := r.layout()
+	var units []Unit
+	for _, slot := range lay.extra[row*lay.size+col] {
+		units = append(units, lay.units[3*lay.size+slot])
+	}
+	return units
+}
+
+// Index of the box, or jigsaw region, holding a cell
+func (r Rules) RegionOf(row, col int) int {
+	lay := r.layout()
+	return lay.region[row*lay.size+col]
+}
+
+// Region index of every cell of a 9x9 board
+func (r Rules) RegionMap() Regions {
+	if r.Regions == nil {
+		return BoxRegions()
+	}
+	return *r.Regions
+}
+
+// Layouts built for recent rules, so repeated solves of the same jigsaw
+// do not rebuild them. Cleared when it grows past maxLayouts.
+var layoutCache = struct {
+	sync.Mutex
+	layouts map[layoutKey]*layout
+}{layouts: make(map[layoutKey]*layout)}
+
+const maxLayouts = 64
+
+// The parts of the rules a layout is built from
+type layoutKey struct {
+	box                BoxShape
+	jigsaw             bool
+	regions            Regions
+	diagonals, windows bool
+}
+
+func (r Rules) layout() *layout {
+	box := r.BoxShape()
+	if box == boxShapes[9] && r.Regions == nil && !r.Diagonals && !r.Windows {
+		return classicLayout
+	}
+
+	var key layoutKey
+	if box == boxShapes[9] {
+		key = layoutKey{box, r.Jigsaw(), r.RegionMap(), r.Diagonals, r.Windows}
+	} else {
+		key = layoutKey{box: box}
+	}
+	layoutCache.Lock()
+	defer layoutCache.Unlock()
+	if l, ok := layoutCache.layouts[key]; ok {
+		return l
+	}
+	if len(layoutCache.layouts) >= maxLayouts {
+		clear(layoutCache.layouts)
+	}
+	l := newLayout(r)
+	layoutCache.layouts[key] = l
+	return l
+}
+
+// Check if the rules are those of classic 9x9 Sudoku
+func (r Rules) classic() bool {
+	return r.BoxShape() == boxShapes[9] && r.Regions == nil && len(r.Cages) == 0 && !r.Diagonals && !r.Windows
+}
+
+// Point a solver engine at these rules. Engines from outside this package
+// only know classic rules, so the backtracker takes over from them for
+// any other rules. It also takes over killers from DLX, which cannot add
+// up cages. Boards larger than maxSearchSize go to DLX, as backtracking
+// can run for minutes on them.
+func (r Rules) engine(s Solver) Solver {
+	switch s := s.(type) {
+	case BacktrackSolver:
+		if r.Size() <= maxSearchSize {
+			s.Rules = r
+			return s
+		}
+	case DLXSolver:
+		if !r.Killer() {
+			s.Rules = r
+			return s
+		}
+	}
+	if r.Size() > maxSearchSize {
+		return DLXSolver{Rules: r}
+	}
+	if !r.classic() {
+		return BacktrackSolver{Rules: r}
+	}
+	return s
+}
+
+// Check if a puzzle has exactly one solution under these rules
+func (r Rules) HasUniqueSolution(grid Grid) bool {
+	return isUnique(r.engine(DefaultSolver), grid)
+}
+
+// Solve a puzzle under these rules. Returns false if it has no solution.
+func (r Rules) Solve(grid Grid) (Grid, bool) {
+	return r.engine(DefaultSolver).Solve(grid)
+}
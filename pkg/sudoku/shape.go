@@ -0,0 +1,50 @@
+package sudoku
+
+import "fmt"
+
+// BoxShape is the height and width of the boxes of a board. A board has as
+// many rows, columns and boxes as a box has cells.
+type BoxShape struct {
+	Rows int `json:"rows"`
+	Cols int `json:"cols"`
+}
+
+// Board sizes that can be played, from small to large
+var Sizes = []int{4, 6, 9, 12, 16}
+
+// Box shape for each board size
+var boxShapes = map[int]BoxShape{
+	4:  {2, 2},
+	6:  {2, 3},
+	9:  {3, 3},
+	12: {3, 4},
+	16: {4, 4},
+}
+
+// Box shape of a board size, e.g. 2x3 boxes for a 6x6 board
+func BoxShapeFor(size int) (BoxShape, error) {
+	b, ok := boxShapes[size]
+	if !ok {
+		return BoxShape{}, fmt.Errorf("unsupported board size %d, want 4, 6, 9, 12 or 16", size)
+	}
+	return b, nil
+}
+
+// Number of rows, columns and digits of a board with these boxes
+func (b BoxShape) Size() int {
+	return b.Rows * b.Cols
+}
+
+func (b BoxShape) String() string {
+	return fmt.Sprintf("%dx%d", b.Rows, b.Cols)
+}
+
+// Box index of a cell, counting across then down
+func (b BoxShape) index(row, col int) int {
+	return (row/b.Rows)*b.Rows + col/b.Cols
+}
+
+// All digits of a board size, as a mask with bit d-1 set for digit d
+func digitMask(size int) uint16 {
+	return uint16(1)<<size - 1
+}
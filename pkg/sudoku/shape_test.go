@@ -0,0 +1,49 @@
+package sudoku
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBoxShapeFor(t *testing.T) {
+	for _, size := range Sizes {
+		box, err := BoxShapeFor(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if box.Size() != size {
+			t.Errorf("%s boxes make a board of %d, want %d", box, box.Size(), size)
+		}
+	}
+	if _, err := BoxShapeFor(10); err == nil {
+		t.Error("size 10 accepted")
+	}
+}
+
+func TestGenerateSizes(t *testing.T) {
+	for _, size := range Sizes {
+		t.Run(fmt.Sprintf("%dx%d", size, size), func(t *testing.T) {
+			s, err := Generate(Medium, WithSize(size), WithSeed(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Size() != size {
+				t.Fatalf("board size %d, want %d", s.Size(), size)
+			}
+			if !s.Rules.HasUniqueSolution(s.Grid) {
+				t.Fatal("puzzle does not have a unique solution")
+			}
+			if s.Grid.Clues() == size*size {
+				t.Fatal("no cells were cleared")
+			}
+			for i := range MaxSize {
+				for j := range MaxSize {
+					v := s.Solution[i][j]
+					if (i < size && j < size) != (v != 0) || v > size {
+						t.Fatalf("solution has %d at %s", v, Cell{i, j})
+					}
+				}
+			}
+		})
+	}
+}
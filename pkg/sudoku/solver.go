@@ -0,0 +1,89 @@
+package sudoku
+
+import "math/rand"
+
+// Solver is a complete search engine for puzzles. The generator and the
+// uniqueness checks work with any implementation.
+type Solver interface {
+	// Solve returns a solution of the grid, or false if it has none
+	Solve(grid Grid) (Grid, bool)
+
+	// CountSolutions counts the solutions of the grid, stopping at limit
+	CountSolutions(grid Grid, limit int) int
+}
+
+// Solver used when none is chosen explicitly
+var DefaultSolver Solver = BacktrackSolver{}
+
+// Solve a grid with the default solver. Returns false if it has no solution.
+func Solve(grid Grid) (Grid, bool) {
+	return DefaultSolver.Solve(grid)
+}
+
+// Count the solutions of a grid with the default solver, stopping at limit
+func CountSolutions(grid Grid, limit int) int {
+	return DefaultSolver.CountSolutions(grid, limit)
+}
+
+// Check if a puzzle has exactly one solution using the given solver
+func isUnique(solver Solver, grid Grid) bool {
+	return solver.CountSolutions(grid, 2) == 1
+}
+
+// BacktrackSolver is a backtracking search over bitmask candidate sets
+type BacktrackSolver struct {
+	Rules Rules // Units to search under; the zero value is classic Sudoku
+}
+
+func (s BacktrackSolver) Solve(grid Grid) (Grid, bool) {
+	used, ok := s.Rules.usedTables(&grid)
+	if !ok {
+		return grid, false
+	}
+
+	// The search tries digits in random order; a fixed seed keeps Solve
+	// deterministic
+	rng := rand.New(rand.NewSource(0))
+	if !solveSudokuFast(rng, &grid, &used) {
+		return grid, false
+	}
+	return grid, true
+}
+
+func (s BacktrackSolver) CountSolutions(grid Grid, limit int) int {
+	used, ok := s.Rules.usedTables(&grid)
+	if !ok {
+		return 0
+	}
+
+	solutions := 0
+	countSolutions(&grid, &solutions, limit, &used)
+	return solutions
+}
+
+// Build the classic used-digit masks for a grid. Returns false if a digit
+// appears twice in a row, column or box.
+func usedTables(grid *Grid) (usedMasks, bool) {
+	return Rules{}.usedTables(grid)
+}
+
+// Build the used-digit masks for a grid under these rules. Returns false
+// if a digit appears twice in a unit or breaks a cage.
+func (r Rules) usedTables(grid *Grid) (usedMasks, bool) {
+	used := newUsedMasks(r.layout())
+	used.addCages(r.Cages)
+	size := r.Size()
+	for i := range size {
+		for j := range size {
+			num := grid[i][j]
+			if num == 0 {
+				continue
+			}
+			if used.candidates(i, j)&digitBit(num) == 0 {
+				return used, false
+			}
+			used.set(i, j, num)
+		}
+	}
+	return used, true
+}
@@ -0,0 +1,103 @@
+package sudoku
+
+import "testing"
+
+var testSolvers = []struct {
+	name   string
+	solver Solver
+}{
+	{"Backtrack", BacktrackSolver{}},
+	{"DLX", DLXSolver{}},
+}
+
+func TestSolversSolve(t *testing.T) {
+	grid := parseTestGrid(t, "003020600900305001001806400008102900700000008006708200002609500800203009005010300")
+
+	for _, s := range testSolvers {
+		t.Run(s.name, func(t *testing.T) {
+			solution, ok := s.solver.Solve(grid)
+			if !ok {
+				t.Fatal("no solution found")
+			}
+			if !isValidSudokuGrid(&solution) {
+				t.Fatal("invalid solution")
+			}
+			for i := range 9 {
+				for j := range 9 {
+					if grid[i][j] != 0 && solution[i][j] != grid[i][j] {
+						t.Fatalf("given at r%dc%d was changed", i+1, j+1)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSolversCountSolutions(t *testing.T) {
+	unique := parseTestGrid(t, "003020600900305001001806400008102900700000008006708200002609500800203009005010300")
+
+	var conflicting Grid
+	conflicting[0][0] = 4
+	conflicting[1][1] = 4
+
+	var empty Grid
+
+	tests := []struct {
+		name  string
+		grid  Grid
+		limit int
+		want  int
+	}{
+		{"Unique", unique, 2, 1},
+		{"Conflicting", conflicting, 2, 0},
+		{"EmptyStopsAtLimit", empty, 5, 5},
+	}
+
+	for _, s := range testSolvers {
+		for _, tt := range tests {
+			t.Run(s.name+"/"+tt.name, func(t *testing.T) {
+				if got := s.solver.CountSolutions(tt.grid, tt.limit); got != tt.want {
+					t.Errorf("got %d solutions, want %d", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+// Both engines must agree on generated puzzles and on the same puzzles with
+// a clue removed, which often have several solutions
+func TestSolversAgree(t *testing.T) {
+	for seed := range int64(5) {
+		grid := NewWithSeed(Medium, seed).Grid
+
+		variants := []Grid{grid}
+		for i := range 81 {
+			if grid[i/9][i%9] != 0 {
+				loose := grid
+				loose[i/9][i%9] = 0
+				variants = append(variants, loose)
+				break
+			}
+		}
+
+		for _, g := range variants {
+			backtrack := BacktrackSolver{}.CountSolutions(g, 3)
+			dlx := DLXSolver{}.CountSolutions(g, 3)
+			if backtrack != dlx {
+				t.Fatalf("seed %d: backtrack counted %d solutions, DLX %d", seed, backtrack, dlx)
+			}
+		}
+	}
+}
+
+func TestGeneratorWithSolver(t *testing.T) {
+	for _, s := range testSolvers {
+		puzzle, err := Generator{Solver: s.solver}.Generate(Easy, 3)
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if n := countPuzzleSolutions(puzzle.Grid); n != 1 {
+			t.Errorf("%s: generated puzzle has %d solutions", s.name, n)
+		}
+	}
+}
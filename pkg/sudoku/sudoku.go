@@ -0,0 +1,199 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Difficulty levels
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Medium
+	Hard
+	Expert
+)
+
+func (d Difficulty) Next() Difficulty {
+	return (d + 1) % 4
+}
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "Easy"
+	case Medium:
+		return "Medium"
+	case Hard:
+		return "Hard"
+	case Expert:
+		return "Expert"
+	default:
+		return "Unknown"
+	}
+}
+
+// Parse a difficulty name such as "hard", ignoring case
+func ParseDifficulty(name string) (Difficulty, error) {
+	for d := Easy; d <= Expert; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return Easy, fmt.Errorf("unknown difficulty %q", name)
+}
+
+// Sudoku grid and game state
+type Sudoku struct {
+	Grid     Grid                     // Current grid state
+	Solution Grid                     // Complete solution
+	Initial  [MaxSize][MaxSize]bool   // Which cells were given initially
+	Notes    [MaxSize][MaxSize]uint16 // Pencil marks per cell, bit d-1 set for digit d
+	Rules    Rules                    // Board size and units the puzzle is played on
+	Seed     int64                    // Seed the puzzle was generated from
+	CursorX  int
+	CursorY  int
+}
+
+// Seeds picked at random are kept short so they are easy to share
+const maxRandomSeed = 1_000_000
+
+// Pick a random seed in the range used when no seed is given
+func RandomSeed() int64 {
+	return rand.Int63n(maxRandomSeed)
+}
+
+// Generate a new Sudoku puzzle from a random seed
+func New(difficulty Difficulty) Sudoku {
+	return NewWithSeed(difficulty, RandomSeed())
+}
+
+// Generate a new Sudoku puzzle whose rating falls in the difficulty's band.
+// The same seed and difficulty always give the same puzzle.
+func NewWithSeed(difficulty Difficulty, seed int64) Sudoku {
+	s, err := Generator{}.Generate(difficulty, seed)
+	if err != nil {
+		// A classic 9x9 puzzle can always be filled and nothing cancels it
+		panic("sudoku: generating a classic puzzle failed: " + err.Error())
+	}
+	return s
+}
+
+// Number of rows, columns and digits of the board
+func (s *Sudoku) Size() int {
+	return s.Rules.Size()
+}
+
+// Grid of the givens alone, without the player's entries
+func (s *Sudoku) Givens() Grid {
+	var givens Grid
+	for i := range s.Grid {
+		for j := range s.Grid[i] {
+			if s.Initial[i][j] {
+				givens[i][j] = s.Grid[i][j]
+			}
+		}
+	}
+	return givens
+}
+
+// Check if the puzzle is solved
+func (s *Sudoku) IsSolved() bool {
+	for i := range s.Grid {
+		for j := range s.Grid[i] {
+			if s.Grid[i][j] != s.Solution[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Get the current cell value (for highlighting)
+func (s *Sudoku) GetCurrentValue() int {
+	return s.Grid[s.CursorY][s.CursorX]
+}
+
+// Move cursor
+func (s *Sudoku) MoveCursor(dx, dy int) {
+	newX := s.CursorX + dx
+	newY := s.CursorY + dy
+	size := s.Size()
+
+	if newX >= 0 && newX < size {
+		s.CursorX = newX
+	}
+	if newY >= 0 && newY < size {
+		s.CursorY = newY
+	}
+}
+
+// Set value at current cursor position
+func (s *Sudoku) SetValue(value int) bool {
+	if s.Initial[s.CursorY][s.CursorX] {
+		return false // Cannot modify initial cells
+	}
+	s.Grid[s.CursorY][s.CursorX] = value
+	if value != 0 {
+		s.Notes[s.CursorY][s.CursorX] = 0
+		s.clearPeerNotes(s.CursorY, s.CursorX, value)
+	}
+	return true
+}
+
+// Toggle a pencil mark at current cursor position
+func (s *Sudoku) ToggleNote(num int) bool {
+	if s.Initial[s.CursorY][s.CursorX] || s.Grid[s.CursorY][s.CursorX] != 0 {
+		return false // Notes only go in empty cells
+	}
+	s.Notes[s.CursorY][s.CursorX] ^= digitBit(num)
+	return true
+}
+
+// Check if a cell has a pencil mark for a digit
+func (s *Sudoku) HasNote(row, col, num int) bool {
+	return s.Notes[row][col]&digitBit(num) != 0
+}
+
+// Check if any cell has pencil marks
+func (s *Sudoku) HasNotes() bool {
+	for i := range s.Notes {
+		for j := range s.Notes[i] {
+			if s.Notes[i][j] != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Remove a placed digit from the notes of every cell in the same row,
+// column, region or cage
+func (s *Sudoku) clearPeerNotes(row, col, num int) {
+	lay := s.Rules.layout()
+	for _, p := range lay.peers[row*lay.size+col] {
+		s.Notes[p/lay.size][p%lay.size] &^= digitBit(num)
+	}
+	if k, ok := s.Rules.CageOf(row, col); ok {
+		for _, c := range s.Rules.Cages[k].Cells {
+			s.Notes[c.Row][c.Col] &^= digitBit(num)
+		}
+	}
+}
+
+// Check if current move is correct
+func (s *Sudoku) IsCurrentMoveCorrect() bool {
+	return s.Grid[s.CursorY][s.CursorX] == s.Solution[s.CursorY][s.CursorX]
+}
+
+// Clear current cell, including its notes
+func (s *Sudoku) ClearCurrentCell() bool {
+	if s.Initial[s.CursorY][s.CursorX] {
+		return false // Cannot modify initial cells
+	}
+	s.Grid[s.CursorY][s.CursorX] = 0
+	s.Notes[s.CursorY][s.CursorX] = 0
+	return true
+}
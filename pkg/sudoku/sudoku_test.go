@@ -0,0 +1,95 @@
+package sudoku
+
+import "testing"
+
+func TestToggleNote(t *testing.T) {
+	s := NewWithSeed(Easy, 1)
+	row, col := firstEmptyCell(t, &s)
+	s.CursorY, s.CursorX = row, col
+
+	if !s.ToggleNote(4) || !s.HasNote(row, col, 4) {
+		t.Fatal("note 4 was not added")
+	}
+	if !s.ToggleNote(4) || s.HasNote(row, col, 4) {
+		t.Fatal("note 4 was not removed")
+	}
+	if s.HasNotes() {
+		t.Fatal("board should have no notes left")
+	}
+
+	// Given cells cannot hold notes
+	for i := range s.Initial {
+		for j := range s.Initial[i] {
+			if s.Initial[i][j] {
+				s.CursorY, s.CursorX = i, j
+				if s.ToggleNote(4) {
+					t.Fatal("note added to a given cell")
+				}
+				return
+			}
+		}
+	}
+}
+
+func TestSetValueClearsPeerNotes(t *testing.T) {
+	s := NewWithSeed(Easy, 1)
+	row, col := firstEmptyCell(t, &s)
+
+	// Mark 5 everywhere it is allowed
+	for i := range 9 {
+		for j := range 9 {
+			if s.Grid[i][j] == 0 {
+				s.Notes[i][j] = digitBit(5) | digitBit(6)
+			}
+		}
+	}
+
+	s.CursorY, s.CursorX = row, col
+	s.SetValue(5)
+
+	if s.Notes[row][col] != 0 {
+		t.Error("notes of the filled cell were kept")
+	}
+	for i := range 9 {
+		for j := range 9 {
+			if s.Grid[i][j] != 0 {
+				continue
+			}
+			peer := i == row || j == col || boxIndex(i, j) == boxIndex(row, col)
+			if peer && s.HasNote(i, j, 5) {
+				t.Errorf("peer r%dc%d still has note 5", i+1, j+1)
+			}
+			if !peer && !s.HasNote(i, j, 5) {
+				t.Errorf("unrelated cell r%dc%d lost note 5", i+1, j+1)
+			}
+			if !s.HasNote(i, j, 6) {
+				t.Errorf("r%dc%d lost note 6", i+1, j+1)
+			}
+		}
+	}
+}
+
+// Helper: position of the first empty cell of a puzzle
+func firstEmptyCell(t *testing.T, s *Sudoku) (int, int) {
+	t.Helper()
+	for i := range 9 {
+		for j := range 9 {
+			if s.Grid[i][j] == 0 {
+				return i, j
+			}
+		}
+	}
+	t.Fatal("puzzle has no empty cells")
+	return 0, 0
+}
+
+func TestGivensLeaveOutEntries(t *testing.T) {
+	s := NewWithSeed(Easy, 1)
+	puzzle := s.Grid
+	s.CursorY, s.CursorX = firstEmptyCell(t, &s)
+	s.SetValue(s.Solution[s.CursorY][s.CursorX]%9 + 1) // A wrong entry
+
+	if s.Givens() != puzzle {
+		t.Error("givens include the entry")
+	}
+}
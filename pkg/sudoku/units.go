@@ -0,0 +1,209 @@
+package sudoku
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Kind of constraint unit
+type UnitKind int
+
+const (
+	BoxUnit UnitKind = iota
+	RowUnit
+	ColumnUnit
+	RegionUnit   // Irregular region of a jigsaw puzzle
+	CageUnit     // Cage of a killer puzzle
+	DiagonalUnit // Main diagonal of a Sudoku X, from the top left first
+	WindowUnit   // Extra 3x3 window of a Hyper Sudoku
+)
+
+func (k UnitKind) String() string {
+	switch k {
+	case BoxUnit:
+		return "box"
+	case RowUnit:
+		return "row"
+	case ColumnUnit:
+		return "column"
+	case RegionUnit:
+		return "region"
+	case CageUnit:
+		return "cage"
+	case DiagonalUnit:
+		return "diagonal"
+	case WindowUnit:
+		return "window"
+	default:
+		return "unit"
+	}
+}
+
+// A unit is a group of cells that must hold every digit exactly once
+type Unit struct {
+	Kind  UnitKind
+	Index int // 0-based index within its kind
+}
+
+func (u Unit) String() string {
+	return fmt.Sprintf("%s %d", u.Kind, u.Index+1)
+}
+
+// A cell position on the board
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("r%dc%d", c.Row+1, c.Col+1)
+}
+
+// Layout describes which cells belong to which units. Cells are addressed
+// by their flat index row*size+col.
+type layout struct {
+	size      int
+	digits    uint16 // every digit of the board, bit d-1 set for digit d
+	units     []Unit
+	unitCells [][]int // cells of each unit
+	cellUnits [][]int // units each cell belongs to
+	peers     [][]int // cells sharing a unit with each cell
+	isPeer    [][]bool
+	rows      []int   // unit indices of the rows, in order
+	cols      []int   // unit indices of the columns, in order
+	region    []int   // region of each cell, 0-based
+	extra     [][]int // slots of the diagonals and windows each cell is in
+
+	dlxOnce    sync.Once
+	dlxBase    *dlx  // Empty exact cover matrix, copied by each DLX search
+	dlxRowNode []int // First node of each candidate's row in dlxBase
+}
+
+var classicLayout = newLayout(Rules{})
+
+// Top left corners of the windows of a Hyper Sudoku
+var windowCorners = [4]Cell{{1, 1}, {1, 5}, {5, 1}, {5, 5}}
+
+// Diagonals and windows a layout can add to its units
+const maxExtraUnits = 6
+
+// Build the layout for the units of a set of rules: their boxes, or
+// jigsaw regions, then rows and columns, then any diagonals and windows.
+// Regions come first so that scans which walk the units in order look at
+// boxes before lines, like a person would.
+func newLayout(r Rules) *layout {
+	size := r.Size()
+	n := size * size
+	l := &layout{size: size, digits: digitMask(size)}
+
+	box := r.BoxShape()
+	l.region = make([]int, n)
+	l.extra = make([][]int, n)
+	for i := range size {
+		for j := range size {
+			if r.Regions != nil {
+				l.region[i*size+j] = r.Regions[i][j]
+			} else {
+				l.region[i*size+j] = box.index(i, j)
+			}
+		}
+	}
+
+	kind := BoxUnit
+	if r.Regions != nil {
+		kind = RegionUnit
+	}
+	for b := range size {
+		cells := make([]int, 0, size)
+		for c, reg := range l.region {
+			if reg == b {
+				cells = append(cells, c)
+			}
+		}
+		l.addUnit(Unit{kind, b}, cells)
+	}
+	for row := range size {
+		cells := make([]int, 0, size)
+		for col := range size {
+			cells = append(cells, row*size+col)
+		}
+		l.rows = append(l.rows, len(l.units))
+		l.addUnit(Unit{RowUnit, row}, cells)
+	}
+	for col := range size {
+		cells := make([]int, 0, size)
+		for row := range size {
+			cells = append(cells, row*size+col)
+		}
+		l.cols = append(l.cols, len(l.units))
+		l.addUnit(Unit{ColumnUnit, col}, cells)
+	}
+
+	if r.Diagonals {
+		var main, anti []int
+		for i := range size {
+			main = append(main, i*size+i)
+			anti = append(anti, i*size+size-1-i)
+		}
+		l.addExtraUnit(Unit{DiagonalUnit, 0}, main)
+		l.addExtraUnit(Unit{DiagonalUnit, 1}, anti)
+	}
+	if r.Windows {
+		for w, corner := range windowCorners {
+			cells := make([]int, 0, size)
+			for i := range 3 {
+				for j := range 3 {
+					cells = append(cells, (corner.Row+i)*size+corner.Col+j)
+				}
+			}
+			l.addExtraUnit(Unit{WindowUnit, w}, cells)
+		}
+	}
+
+	l.buildPeers()
+	return l
+}
+
+func (l *layout) addUnit(u Unit, cells []int) {
+	l.units = append(l.units, u)
+	l.unitCells = append(l.unitCells, cells)
+}
+
+// Add a unit beyond the regions, rows and columns. Each gets its own slot
+// of used digits in the search.
+func (l *layout) addExtraUnit(u Unit, cells []int) {
+	slot := len(l.units) - 3*l.size
+	for _, c := range cells {
+		l.extra[c] = append(l.extra[c], slot)
+	}
+	l.addUnit(u, cells)
+}
+
+// Derive cellUnits and peers from the unit list
+func (l *layout) buildPeers() {
+	n := l.size * l.size
+	l.cellUnits = make([][]int, n)
+	for u, cells := range l.unitCells {
+		for _, c := range cells {
+			l.cellUnits[c] = append(l.cellUnits[c], u)
+		}
+	}
+
+	l.peers = make([][]int, n)
+	l.isPeer = make([][]bool, n)
+	for c := range n {
+		l.isPeer[c] = make([]bool, n)
+		for _, u := range l.cellUnits[c] {
+			for _, p := range l.unitCells[u] {
+				if p != c && !l.isPeer[c][p] {
+					l.isPeer[c][p] = true
+					l.peers[c] = append(l.peers[c], p)
+				}
+			}
+		}
+	}
+}
+
+func (l *layout) cell(idx int) Cell {
+	return Cell{idx / l.size, idx % l.size}
+}
@@ -0,0 +1,97 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Variant is a kind of puzzle, picking the rules a new puzzle is
+// generated with
+type Variant int
+
+const (
+	VariantClassic Variant = iota
+	VariantJigsaw          // Irregular regions instead of boxes
+	VariantKiller          // Sum cages with few or no givens
+	VariantX               // Both main diagonals hold every digit
+	VariantHyper           // Four extra 3x3 windows hold every digit
+)
+
+var variantNames = []string{"Classic", "Jigsaw", "Killer", "X", "Hyper"}
+
+func (v Variant) String() string {
+	if v < 0 || int(v) >= len(variantNames) {
+		return "unknown"
+	}
+	return variantNames[v]
+}
+
+// Cycle to the next variant
+func (v Variant) Next() Variant {
+	return (v + 1) % Variant(len(variantNames))
+}
+
+// Parse a variant name such as "killer", ignoring case
+func ParseVariant(name string) (Variant, error) {
+	for v, n := range variantNames {
+		if strings.EqualFold(name, n) {
+			return Variant(v), nil
+		}
+	}
+	return VariantClassic, fmt.Errorf("unknown variant %q", name)
+}
+
+// Generation options for a puzzle of this variant
+func (v Variant) Options() []Option {
+	switch v {
+	case VariantJigsaw:
+		return []Option{WithJigsaw()}
+	case VariantKiller:
+		return []Option{WithKiller()}
+	case VariantX:
+		return []Option{WithDiagonals()}
+	case VariantHyper:
+		return []Option{WithWindows()}
+	default:
+		return nil
+	}
+}
+
+// The variant the rules belong to. Cages make a killer whatever its
+// regions, and irregular regions make a jigsaw.
+func (r Rules) Variant() Variant {
+	switch {
+	case r.Killer():
+		return VariantKiller
+	case r.Jigsaw():
+		return VariantJigsaw
+	case r.Diagonals:
+		return VariantX
+	case r.Windows:
+		return VariantHyper
+	default:
+		return VariantClassic
+	}
+}
+
+// Generation options for more puzzles under the same rules. Jigsaws get
+// fresh regions and killers fresh cages.
+func (r Rules) Options() []Option {
+	var opts []Option
+	if size := r.Size(); size != 9 {
+		opts = append(opts, WithSize(size))
+	}
+	if r.Jigsaw() {
+		opts = append(opts, WithJigsaw())
+	}
+	if r.Killer() {
+		opts = append(opts, WithKiller())
+	}
+	if r.Diagonals {
+		opts = append(opts, WithDiagonals())
+	}
+	if r.Windows {
+		opts = append(opts, WithWindows())
+	}
+	return opts
+}
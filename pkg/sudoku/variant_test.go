@@ -0,0 +1,88 @@
+package sudoku
+
+import "testing"
+
+func TestParseVariant(t *testing.T) {
+	for v := VariantClassic; v <= VariantHyper; v++ {
+		if got, err := ParseVariant(v.String()); err != nil || got != v {
+			t.Errorf("ParseVariant(%q) = %s, %v", v, got, err)
+		}
+	}
+	if got, err := ParseVariant("hyper"); err != nil || got != VariantHyper {
+		t.Errorf("lower case name gave %s, %v", got, err)
+	}
+	if _, err := ParseVariant("samurai"); err == nil {
+		t.Error("expected an error for an unknown variant")
+	}
+	if VariantHyper.Next() != VariantClassic {
+		t.Error("Next does not wrap around")
+	}
+}
+
+func TestGenerateExtraUnits(t *testing.T) {
+	for _, v := range []Variant{VariantX, VariantHyper} {
+		s, err := Generate(Medium, append(v.Options(), WithSeed(2))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Rules.Variant() != v {
+			t.Fatalf("%s: generated a %s puzzle", v, s.Rules.Variant())
+		}
+
+		// The solution must hold every digit once per unit, extra units
+		// included
+		lay := s.Rules.layout()
+		if len(lay.units) == 27 {
+			t.Fatalf("%s: layout has no extra units", v)
+		}
+		for u, cells := range lay.unitCells {
+			var seen uint16
+			for _, c := range cells {
+				seen |= digitBit(s.Solution[c/9][c%9])
+			}
+			if seen != allDigits {
+				t.Fatalf("%s: solution repeats a digit in %s\n%s", v, lay.units[u], s.Solution)
+			}
+		}
+
+		for _, solver := range []Solver{BacktrackSolver{Rules: s.Rules}, DLXSolver{Rules: s.Rules}} {
+			if n := solver.CountSolutions(s.Grid, 2); n != 1 {
+				t.Errorf("%s: %T counts %d solutions", v, solver, n)
+			}
+		}
+	}
+}
+
+func TestExtraUnits(t *testing.T) {
+	r := Rules{Diagonals: true, Windows: true}
+	if got := r.ExtraUnits(4, 4); len(got) != 2 {
+		t.Errorf("centre lies in %v, want both diagonals", got)
+	}
+	if got := r.ExtraUnits(1, 1); len(got) != 2 || got[0] != (Unit{DiagonalUnit, 0}) || got[1] != (Unit{WindowUnit, 0}) {
+		t.Errorf("r2c2 lies in %v, want diagonal 1 and window 1", got)
+	}
+	if got := r.ExtraUnits(0, 4); len(got) != 0 {
+		t.Errorf("r1c5 lies in %v, want none", got)
+	}
+	if got := (Rules{}).ExtraUnits(4, 4); len(got) != 0 {
+		t.Errorf("classic centre lies in %v, want none", got)
+	}
+}
+
+func TestExtraUnitsExportRoundTrip(t *testing.T) {
+	s, err := Generate(Easy, WithSeed(3), WithDiagonals(), WithWindows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Export(FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Import(data, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Rules.Diagonals || !got.Rules.Windows || got.Solution != s.Solution {
+		t.Error("diagonals, windows or solution lost in the JSON round trip")
+	}
+}